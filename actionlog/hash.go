@@ -0,0 +1,49 @@
+package actionlog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/nheyn/go-redux/store"
+)
+
+// A HashUpdater is an Updater that can create its own hash, for use in a StateHash. Updaters that do not
+// implement it are hashed using their Go-syntax representation (i.e. fmt.Sprintf("%#v", ...)), so they
+// should not contain pointers.
+type HashUpdater interface {
+	store.Updater
+
+	// Creates a string that will be the same for all Updaters that contain the same data.
+	Hash() string
+}
+
+// A StateHash contains the hash of each Updater in a State, keyed by a string version of its key.
+type StateHash map[string]string
+
+// Creates the StateHash for the given State.
+func HashState(st store.State) StateHash {
+	hash := StateHash{}
+	for key, data := range st {
+		hash[hashKey(key)] = hashUpdater(data)
+	}
+
+	return hash
+}
+
+// Creates the key to use, in a StateHash, for the given key from a State.
+func hashKey(key interface{}) string {
+	return fmt.Sprintf("%#v", key)
+}
+
+// Creates the hash for the given Updater.
+func hashUpdater(data store.Updater) string {
+	var repr string
+	if hashData, isHashUpdater := data.(HashUpdater); isHashUpdater {
+		repr = hashData.Hash()
+	} else {
+		repr = fmt.Sprintf("%#v", data)
+	}
+
+	sum := sha256.Sum256([]byte(repr))
+	return hex.EncodeToString(sum[:])
+}
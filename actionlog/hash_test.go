@@ -0,0 +1,49 @@
+package actionlog
+
+import (
+	"context"
+	"github.com/nheyn/go-redux/store"
+	"testing"
+)
+
+type testHashUpdater struct {
+	id      int
+	ignored int
+}
+
+func (u testHashUpdater) Update(_ context.Context, _ interface{}) (store.Updater, error) {
+	return u, nil
+}
+
+func (u testHashUpdater) Hash() string {
+	return string(rune(u.id))
+}
+
+func TestHashStateHashesEachKey(t *testing.T) {
+	hash := HashState(store.State{
+		"count 0": testCounter(1),
+		"count 1": testCounter(1),
+		"count 2": testCounter(2),
+	})
+
+	if len(hash) != 3 {
+		t.Fatal("The hash should have 3 keys, but has", len(hash))
+	}
+	if hash[hashKey("count 0")] != hash[hashKey("count 1")] {
+		t.Error("Equal Updaters should have the same hash")
+	}
+	if hash[hashKey("count 0")] == hash[hashKey("count 2")] {
+		t.Error("Different Updaters should have different hashes")
+	}
+}
+
+func TestHashStateUsesHashUpdaters(t *testing.T) {
+	hash := HashState(store.State{
+		"updater 0": testHashUpdater{1, 1},
+		"updater 1": testHashUpdater{1, 2},
+	})
+
+	if hash[hashKey("updater 0")] != hash[hashKey("updater 1")] {
+		t.Error("The Hash() method should have been used to hash the Updaters")
+	}
+}
@@ -0,0 +1,75 @@
+package actionlog
+
+import (
+	"github.com/nheyn/go-redux/store"
+	"sync"
+	"time"
+)
+
+// A Recorder writes an Entry, to a Sink, for every action that is dispatched to a Store.
+// Ex)
+//	sink := &actionlog.MemorySink{}
+//	s := store.New(initialState, actionlog.NewRecorder(sink).Config)
+type Recorder struct {
+	sink Sink
+
+	mu    sync.Mutex
+	index uint64
+	err   error
+}
+
+// Creates a Recorder that writes to the given Sink.
+func NewRecorder(sink Sink) *Recorder {
+	return &Recorder{sink: sink}
+}
+
+// Config is a config function for store.New(...), it will make the Store record its actions.
+func (r *Recorder) Config(s *store.Store) {
+	store.OnDispatched(func(change store.Change) {
+		e := Entry{
+			Sequence: change.Sequence,
+			Action:   change.Action,
+			Hash:     HashState(change.Current),
+		}
+		if change.Partial != nil {
+			e.Err = change.Partial.Error()
+			e.Partial = true
+		}
+
+		r.write(e)
+	})(s)
+
+	store.OnFailure(func(action interface{}, err error) {
+		// Partial commits are recorded with the Change
+		if store.IsPartialCommit(err) {
+			return
+		}
+
+		r.write(Entry{
+			Action: action,
+			Err:    err.Error(),
+		})
+	})(s)
+}
+
+// Err returns the first error returned by the Sink.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+// Adds the index and time to the given Entry, and writes it to the Sink.
+func (r *Recorder) write(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e.Index = r.index
+	e.Time = time.Now()
+	r.index++
+
+	if err := r.sink.Write(e); err != nil && r.err == nil {
+		r.err = err
+	}
+}
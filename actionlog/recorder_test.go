@@ -0,0 +1,98 @@
+package actionlog
+
+import (
+	"context"
+	"errors"
+	"github.com/nheyn/go-redux/store"
+	"testing"
+)
+
+type testCounter int
+
+func (c testCounter) Update(_ context.Context, action interface{}) (store.Updater, error) {
+	amount, isInt := action.(int)
+	if !isInt {
+		return c, nil
+	}
+	if amount < 0 {
+		return nil, errors.New("Do not use negitive amounts")
+	}
+
+	return c + testCounter(amount), nil
+}
+
+func recordActions(t *testing.T, initialState store.State, actions ...interface{}) []Entry {
+	sink := &MemorySink{}
+	recorder := NewRecorder(sink)
+
+	s := store.New(initialState, recorder.Config)
+	for _, action := range actions {
+		s.Dispatch(context.Background(), action)
+	}
+	s.Close()
+
+	if err := recorder.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return sink.Entries()
+}
+
+func TestRecorderWritesEveryAction(t *testing.T) {
+	entries := recordActions(t, store.State{"count": testCounter(0)}, 1, -1, 2)
+
+	if len(entries) != 3 {
+		t.Fatal("3 entries should have been recorded, but", len(entries), "were")
+	}
+
+	for i, e := range entries {
+		if e.Index != uint64(i) {
+			t.Error("The entry at index", i, "has the index", e.Index)
+		}
+		if e.Time.IsZero() {
+			t.Error("The entry at index", i, "does not have a time")
+		}
+	}
+
+	if entries[0].Sequence != 1 || entries[0].Err != "" || entries[0].Hash == nil {
+		t.Error("The first entry should be for a successful action, but is", entries[0])
+	}
+	if entries[1].Sequence != 0 || entries[1].Err == "" || entries[1].Hash != nil {
+		t.Error("The second entry should be for a failed action, but is", entries[1])
+	}
+	if entries[2].Sequence != 2 || entries[2].Action != 2 {
+		t.Error("The third entry should be for the last action, but is", entries[2])
+	}
+}
+
+func TestRecorderWritesActionsThatChangeNothing(t *testing.T) {
+	entries := recordActions(t, store.State{"count": testCounter(0)}, 1, "ignored")
+
+	if len(entries) != 2 {
+		t.Fatal("2 entries should have been recorded, but", len(entries), "were")
+	}
+	if entries[1].Action != "ignored" || entries[1].Sequence != 0 || entries[1].Err != "" {
+		t.Error("The second entry should be for an action without a sequence, but is", entries[1])
+	}
+	if _, isDifferent := firstDifference(entries[0].Hash, entries[1].Hash); isDifferent {
+		t.Error("The action that changed nothing should have the same hash as the previous one")
+	}
+}
+
+type testFailingSink struct{}
+
+func (testFailingSink) Write(_ Entry) error {
+	return errors.New("test sink error")
+}
+
+func TestRecorderReportsSinkErrors(t *testing.T) {
+	recorder := NewRecorder(testFailingSink{})
+
+	s := store.New(store.State{"count": testCounter(0)}, recorder.Config)
+	s.Dispatch(context.Background(), 1)
+	s.Close()
+
+	if recorder.Err() == nil {
+		t.Error("The recorder should have reported the error from the sink")
+	}
+}
@@ -0,0 +1,99 @@
+package actionlog
+
+import (
+	"context"
+	"fmt"
+	"github.com/nheyn/go-redux/store"
+	"sort"
+)
+
+// A Divergence describes the first Entry where a replay did not match the recorded log.
+type Divergence struct {
+	// The Entry that did not match.
+	Entry Entry
+	// The key, from the StateHash, of the first Updater that did not match. It is "" if the outcome of the
+	// dispatch did not match.
+	Key string
+	// The error returned when the action was replayed, or "" if it succeeded.
+	Err string
+}
+
+func (d *Divergence) Error() string {
+	if d.Key == "" {
+		return fmt.Sprintf(
+			"actionlog: entry %d (%#v) was recorded with error %q, but replayed with %q",
+			d.Entry.Index, d.Entry.Action, d.Entry.Err, d.Err,
+		)
+	}
+
+	return fmt.Sprintf("actionlog: entry %d (%#v) diverged at key %s", d.Entry.Index, d.Entry.Action, d.Key)
+}
+
+// Replay dispatches the actions in the given Entries to a new Store, created with the given State and
+// config functions. The resulting State of each action is checked against its recorded hash. If they do
+// not match, a *Divergence is returned for the first Entry that did not match. The Entries that were
+// partially committed are dispatched using store.WithPartialCommit(...). The Store is shutdown before
+// Replay returns.
+func Replay(ctx context.Context, entries []Entry, initialState store.State, configs ...func(*store.Store)) error {
+	var lastHash StateHash
+	recordHash := store.OnDispatched(func(change store.Change) {
+		lastHash = HashState(change.Current)
+	})
+
+	s := store.New(initialState, append(configs, recordHash)...)
+	defer s.Close()
+
+	for _, e := range entries {
+		lastHash = nil
+
+		dispatchCtx := ctx
+		if e.Partial {
+			dispatchCtx = store.WithPartialCommit(ctx)
+		}
+
+		var err error
+		if batch, isBatch := e.Action.(store.Batch); isBatch {
+			err = s.DispatchBatch(dispatchCtx, batch...)
+		} else {
+			err = s.Dispatch(dispatchCtx, e.Action)
+		}
+
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		}
+		if errMsg != e.Err {
+			return &Divergence{Entry: e, Err: errMsg}
+		}
+
+		if key, isDifferent := firstDifference(e.Hash, lastHash); isDifferent {
+			return &Divergence{Entry: e, Key: key, Err: errMsg}
+		}
+	}
+
+	return nil
+}
+
+// Finds the first key, in sorted order, where the given StateHashes are different.
+func firstDifference(recorded, replayed StateHash) (string, bool) {
+	keys := make([]string, 0, len(recorded)+len(replayed))
+	for key := range recorded {
+		keys = append(keys, key)
+	}
+	for key := range replayed {
+		if _, hasKey := recorded[key]; !hasKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		recordedHash, hasRecorded := recorded[key]
+		replayedHash, hasReplayed := replayed[key]
+		if hasRecorded != hasReplayed || recordedHash != replayedHash {
+			return key, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,84 @@
+package actionlog
+
+import (
+	"context"
+	"errors"
+	"github.com/nheyn/go-redux/store"
+	"testing"
+)
+
+func TestReplayMatchesTheRecordedLog(t *testing.T) {
+	initialState := store.State{"count": testCounter(0)}
+	entries := recordActions(t, initialState, 1, -1, 2, "ignored")
+
+	if err := Replay(context.Background(), entries, initialState); err != nil {
+		t.Error("Replaying the recorded log should not diverge, but returned", err)
+	}
+}
+
+type testFailingUpdater struct{}
+
+func (testFailingUpdater) Update(_ context.Context, _ interface{}) (store.Updater, error) {
+	return nil, errors.New("test updater error")
+}
+
+func TestReplayMatchesPartialCommits(t *testing.T) {
+	initialState := store.State{"count": testCounter(0), "failing": testFailingUpdater{}}
+
+	sink := &MemorySink{}
+	s := store.New(initialState, NewRecorder(sink).Config)
+	s.Dispatch(store.WithPartialCommit(context.Background()), 1)
+	s.Close()
+
+	entries := sink.Entries()
+	if len(entries) != 1 || !entries[0].Partial || entries[0].Err == "" || entries[0].Sequence != 1 {
+		t.Fatal("The partial commit should have been recorded once, but got", entries)
+	}
+	if err := Replay(context.Background(), entries, initialState); err != nil {
+		t.Error("Replaying a partial commit should not diverge, but returned", err)
+	}
+}
+
+func TestReplayReportsTheFirstDivergence(t *testing.T) {
+	initialState := store.State{
+		"count": testCounter(0),
+		"other": testCounter(0),
+	}
+	entries := recordActions(t, initialState, 1, 2, 3)
+
+	changedState := store.State{
+		"count": testCounter(0),
+		"other": testCounter(10),
+	}
+	err := Replay(context.Background(), entries, changedState)
+
+	var divergence *Divergence
+	if !errors.As(err, &divergence) {
+		t.Fatal("Replay should have returned a *Divergence, but returned", err)
+	}
+	if divergence.Entry.Index != 0 {
+		t.Error("The first entry should have diverged, but entry", divergence.Entry.Index, "did")
+	}
+	if divergence.Key != hashKey("other") {
+		t.Error("The other key should have diverged, but", divergence.Key, "did")
+	}
+}
+
+func TestReplayReportsDifferentOutcomes(t *testing.T) {
+	entries := recordActions(t, store.State{"count": testCounter(0)}, 1)
+
+	failing := func(s *store.Store) {
+		s.PerformDispatch = func(_ context.Context, _ store.State, _ interface{}) (store.State, error) {
+			return nil, errors.New("test replay error")
+		}
+	}
+	err := Replay(context.Background(), entries, store.State{"count": testCounter(0)}, failing)
+
+	var divergence *Divergence
+	if !errors.As(err, &divergence) {
+		t.Fatal("Replay should have returned a *Divergence, but returned", err)
+	}
+	if divergence.Key != "" || divergence.Err != "test replay error" {
+		t.Error("The outcome of the first entry should have diverged, but", divergence, "was returned")
+	}
+}
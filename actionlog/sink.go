@@ -0,0 +1,57 @@
+package actionlog
+
+import (
+	"sync"
+	"time"
+)
+
+// An Entry is a record of an action that was dispatched to a Store.
+type Entry struct {
+	// The position of the Entry in the log.
+	Index uint64
+	// The sequence of the Change caused by the action (see store.Change.Sequence), or 0 if it failed or did
+	// not change the State.
+	Sequence uint64
+	// When the action was performed.
+	Time time.Time
+	// The action that was dispatched, for a batch this is a store.Batch.
+	Action interface{}
+	// The error returned by the dispatch, or "" if it succeeded.
+	Err string
+	// If the action was partially committed, so Err is for the Updaters that failed (see
+	// store.PartialCommit(...)).
+	Partial bool
+	// The hash of the State after the action was performed, or nil if it failed (and was not partially
+	// committed).
+	Hash StateHash
+}
+
+// A Sink saves the Entries recorded by a Recorder.
+type Sink interface {
+	// Saves the given Entry. It is called on the goroutine that performs the actions for a Store, so it
+	// should not block for long.
+	Write(Entry) error
+}
+
+// A MemorySink is a Sink that keeps the Entries in memory.
+type MemorySink struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Saves the given Entry.
+func (sink *MemorySink) Write(e Entry) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	sink.entries = append(sink.entries, e)
+	return nil
+}
+
+// Entries returns all of the saved Entries, in order.
+func (sink *MemorySink) Entries() []Entry {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	return append([]Entry{}, sink.entries...)
+}
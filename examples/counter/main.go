@@ -11,6 +11,7 @@ func main() {
   s := store.New(store.State{
     "COUNTER_STATE": counter(0),
   })
+  defer s.Close()
 
   initialCountInfo := &countInfo{}
   s.Select(initialCountInfo)
@@ -0,0 +1,69 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "github.com/nheyn/go-redux/middleware"
+  "github.com/nheyn/go-redux/store"
+  "log/slog"
+  "os"
+)
+
+func main() {
+  ctx := context.Background()
+  logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+  s := store.New(store.State{
+    "SESSION_STATE": session{},
+  }, middleware.Apply(middleware.Logging(
+    logger,
+    middleware.LogStateDiffs,
+    middleware.RedactActions(hidePassword),
+    middleware.RedactState(hideToken),
+  )))
+  defer s.Close()
+
+  s.Dispatch(ctx, login{"user", "hunter2"})
+  s.Dispatch(ctx, login{"user", ""})
+  s.Dispatch(ctx, logout{})
+}
+
+type session struct {
+  user  string
+  token string
+}
+
+func (s session) Update(ctx context.Context, action interface{}) (store.Updater, error) {
+  switch a := action.(type) {
+  case login:
+    if a.password == "" {
+      return nil, errors.New("a password is required")
+    }
+
+    middleware.LoggerFrom(ctx).Debug("logging in", "user", a.user)
+    return session{a.user, "secret-token"}, nil
+  case logout:
+    return session{}, nil
+  }
+
+  return s, nil
+}
+
+type login struct {
+  user     string
+  password string
+}
+
+type logout struct{}
+
+func hidePassword(action interface{}) interface{} {
+  if l, isLogin := action.(login); isLogin {
+    return login{l.user, "[REDACTED]"}
+  }
+
+  return action
+}
+
+func hideToken(_ interface{}, data store.Updater) interface{} {
+  return data.(session).user
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "github.com/nheyn/go-redux/store"
+)
+
+// State
+type counterState struct {
+  value int
+}
+
+func (c counterState) isPositive() bool {
+  return c.value > 0
+}
+
+// Actions
+type counterAction struct {
+  amount int
+}
+
+// Reducer
+func updateCounter(_ context.Context, c counterState, action counterAction) (counterState, error) {
+  if action.amount == 0 {
+    return c, errors.New("Do not use zero as the amount for a counter action")
+  }
+
+  return counterState{c.value + action.amount}, nil
+}
+
+func main() {
+  ctx := context.Background()
+  s := store.NewTyped(counterState{}, updateCounter)
+  defer s.Close()
+
+  s.Dispatch(ctx, counterAction{1})
+  s.Dispatch(ctx, counterAction{10})
+
+  count, _ := s.Select()
+  fmt.Println(count.value, "is postive?", count.isPositive())
+
+  err := s.Dispatch(ctx, counterAction{0})
+  fmt.Println("Error when trying to add zero returns:", err)
+}
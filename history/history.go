@@ -0,0 +1,220 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"github.com/nheyn/go-redux/store"
+	"sync"
+)
+
+// ErrNoEntry is returned when the History does not have a State to move the Store to.
+var ErrNoEntry = errors.New("history: no recorded state to jump to")
+
+// ErrNotApplied is returned when the History has not been added to a Store using Config(...).
+var ErrNotApplied = errors.New("history: the history has not been applied to a store")
+
+// A JumpAction is dispatched by a History to move the Store to one of the recorded States. Subscribers
+// can use it to tell when the Store has jumped.
+type JumpAction struct {
+	// The number of undo steps to move, negative to undo and positive to redo.
+	Steps int
+	// The sequence of the recorded State to jump to, only used if Steps is 0.
+	Sequence uint64
+}
+
+// A History records the States of a Store, so the Store can be moved back (and forward) to them.
+// Ex)
+//	h := history.New(history.Limit(100))
+//	s := store.New(initialState, h.Config)
+//
+//	s.Dispatch(ctx, someAction)
+//	h.Undo(ctx)
+type History struct {
+	limit       int
+	notUndoable func(interface{}) bool
+	groupBy     func(interface{}) interface{}
+
+	mu      sync.Mutex
+	store   *store.Store
+	entries []entry
+	cursor  int
+}
+
+// A recorded State.
+type entry struct {
+	sequence uint64
+	state    store.State
+	group    interface{}
+}
+
+// Creates a new History, configured using the given config functions.
+func New(configs ...func(*History)) *History {
+	h := &History{}
+	for _, config := range configs {
+		config(h)
+	}
+
+	return h
+}
+
+// A config function for a History that will only keep the given number of States.
+func Limit(size int) func(*History) {
+	return func(h *History) {
+		h.limit = size
+	}
+}
+
+// A config function for a History that will not create an undo step for the actions that the given
+// function returns true for, instead the current step will be updated.
+func NotUndoable(isNotUndoable func(action interface{}) bool) func(*History) {
+	return func(h *History) {
+		h.notUndoable = isNotUndoable
+	}
+}
+
+// A config function for a History that will group actions into a single undo step. If the given function
+// returns the same (non-nil) group for consecutive actions, they will be undone together.
+func GroupBy(group func(action interface{}) interface{}) func(*History) {
+	return func(h *History) {
+		h.groupBy = group
+	}
+}
+
+// Config is a config function for store.New(...), it will record the States of the Store and handle the
+// JumpActions.
+func (h *History) Config(s *store.Store) {
+	h.mu.Lock()
+	h.store = s
+	h.mu.Unlock()
+
+	performDispatch := s.PerformDispatch
+	s.PerformDispatch = func(ctx context.Context, st store.State, action interface{}) (store.State, error) {
+		jump, isJump := action.(JumpAction)
+		if !isJump {
+			return performDispatch(ctx, st, action)
+		}
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		target, err := h.target(jump)
+		if err != nil {
+			return nil, err
+		}
+
+		return store.ReplaceState(st, h.entries[target].state), nil
+	}
+
+	store.OnDispatched(h.record)(s)
+}
+
+// Undo moves the Store back one step.
+func (h *History) Undo(ctx context.Context) error {
+	return h.dispatch(ctx, JumpAction{Steps: -1})
+}
+
+// Redo moves the Store forward one step, after it has been undone.
+func (h *History) Redo(ctx context.Context) error {
+	return h.dispatch(ctx, JumpAction{Steps: 1})
+}
+
+// JumpTo moves the Store to the State that was recorded after the action with the given sequence (see
+// store.Change.Sequence), 0 is the initial State.
+func (h *History) JumpTo(ctx context.Context, sequence uint64) error {
+	return h.dispatch(ctx, JumpAction{Sequence: sequence})
+}
+
+// Sequences returns the sequences of all the recorded States, and the index of the current one.
+func (h *History) Sequences() ([]uint64, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sequences := make([]uint64, 0, len(h.entries))
+	for _, e := range h.entries {
+		sequences = append(sequences, e.sequence)
+	}
+
+	return sequences, h.cursor
+}
+
+// Dispatches the given JumpAction to the Store.
+func (h *History) dispatch(ctx context.Context, jump JumpAction) error {
+	h.mu.Lock()
+	s := h.store
+	h.mu.Unlock()
+
+	if s == nil {
+		return ErrNotApplied
+	}
+
+	return s.Dispatch(ctx, jump)
+}
+
+// Records the given Change. A JumpAction moves the cursor to the entry it jumped to, even if the State did
+// not change (i.e. the entry has the same State as the current one), and the other actions that did not
+// change the State are not recorded.
+func (h *History) record(change store.Change) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// The actions are performed one at a time, so the target is the same as when the JumpAction was performed
+	if jump, isJump := change.Action.(JumpAction); isJump {
+		if target, err := h.target(jump); err == nil {
+			h.cursor = target
+		}
+		return
+	}
+	if len(change.ChangedKeys) == 0 {
+		return
+	}
+
+	if len(h.entries) == 0 {
+		h.entries = []entry{{sequence: 0, state: change.Previous}}
+	}
+
+	if h.notUndoable != nil && h.notUndoable(change.Action) {
+		h.entries[h.cursor].state = change.Current
+		return
+	}
+
+	var group interface{}
+	if h.groupBy != nil {
+		group = h.groupBy(change.Action)
+	}
+
+	// Any undone steps can no longer be redone
+	h.entries = h.entries[:h.cursor+1]
+
+	curr := &h.entries[h.cursor]
+	if group != nil && group == curr.group && h.cursor > 0 {
+		curr.sequence = change.Sequence
+		curr.state = change.Current
+		return
+	}
+
+	h.entries = append(h.entries, entry{change.Sequence, change.Current, group})
+	if h.limit > 0 && len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+	}
+	h.cursor = len(h.entries) - 1
+}
+
+// Finds the index of the entry the given JumpAction moves to. Must be called with the lock held.
+func (h *History) target(jump JumpAction) (int, error) {
+	if jump.Steps != 0 {
+		target := h.cursor + jump.Steps
+		if target < 0 || target >= len(h.entries) {
+			return 0, ErrNoEntry
+		}
+
+		return target, nil
+	}
+
+	for i, e := range h.entries {
+		if e.sequence == jump.Sequence {
+			return i, nil
+		}
+	}
+
+	return 0, ErrNoEntry
+}
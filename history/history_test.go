@@ -0,0 +1,270 @@
+package history
+
+import (
+	"context"
+	"github.com/nheyn/go-redux/store"
+	"testing"
+)
+
+type testCounter int
+
+func (c testCounter) Update(_ context.Context, action interface{}) (store.Updater, error) {
+	switch a := action.(type) {
+	case int:
+		return c + testCounter(a), nil
+	case testTyping:
+		return c + testCounter(len(a)), nil
+	}
+
+	return c, nil
+}
+
+type testTyping string
+
+func newTestStore(configs ...func(*History)) (*History, *store.Store) {
+	h := New(configs...)
+	s := store.New(store.State{"count": testCounter(0)}, h.Config)
+
+	return h, s
+}
+
+func countOf(t *testing.T, s *store.Store) testCounter {
+	st := store.State{}
+	if err := s.Select(&st); err != nil {
+		t.Fatal(err)
+	}
+
+	return st["count"].(testCounter)
+}
+
+func dispatchAll(t *testing.T, s *store.Store, actions ...interface{}) {
+	for _, action := range actions {
+		if err := s.Dispatch(context.Background(), action); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHistoryCanUndoAndRedo(t *testing.T) {
+	h, s := newTestStore()
+	defer s.Close()
+	ctx := context.Background()
+
+	dispatchAll(t, s, 1, 2, 3)
+
+	if err := h.Undo(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if count := countOf(t, s); count != 3 {
+		t.Error("After undoing, the count should be 3, but is", count)
+	}
+
+	if err := h.Undo(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Redo(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if count := countOf(t, s); count != 3 {
+		t.Error("After redoing, the count should be 3, but is", count)
+	}
+
+	dispatchAll(t, s, 10)
+	if err := h.Redo(ctx); err != ErrNoEntry {
+		t.Error("Redo should not be possible after a new action, but returned", err)
+	}
+	if count := countOf(t, s); count != 13 {
+		t.Error("The count should be 13, but is", count)
+	}
+}
+
+func TestHistoryCanUndoAndRedoAcrossEqualStates(t *testing.T) {
+	h, s := newTestStore()
+	defer s.Close()
+	ctx := context.Background()
+
+	// The State after the second action is equal to the initial State
+	dispatchAll(t, s, 1, -1, 5)
+
+	if err := h.JumpTo(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.JumpTo(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, cursor := h.Sequences(); cursor != 0 {
+		t.Error("Jumping to an equal State should still move the cursor, but it is", cursor)
+	}
+
+	if err := h.Redo(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if count := countOf(t, s); count != 1 {
+		t.Error("After redoing from the initial State, the count should be 1, but is", count)
+	}
+	if err := h.Redo(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Undo(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if count := countOf(t, s); count != 1 {
+		t.Error("After undoing to the equal State, the count should be 1, but is", count)
+	}
+	if _, cursor := h.Sequences(); cursor != 1 {
+		t.Error("After undoing, the cursor should be 1, but it is", cursor)
+	}
+}
+
+func TestHistoryCanJumpToASequence(t *testing.T) {
+	h, s := newTestStore()
+	defer s.Close()
+
+	dispatchAll(t, s, 1, 2, 3)
+
+	if err := h.JumpTo(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	if count := countOf(t, s); count != 0 {
+		t.Error("After jumping to the initial state, the count should be 0, but is", count)
+	}
+
+	if err := h.JumpTo(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	if count := countOf(t, s); count != 3 {
+		t.Error("After jumping to sequence 2, the count should be 3, but is", count)
+	}
+
+	if err := h.JumpTo(context.Background(), 100); err != ErrNoEntry {
+		t.Error("Jumping to a sequence that was not recorded should return ErrNoEntry, but returned", err)
+	}
+}
+
+func TestHistoryRemovesAddedUpdatersWhenUndone(t *testing.T) {
+	h, s := newTestStore()
+	defer s.Close()
+	ctx := context.Background()
+
+	if err := s.AddUpdater(ctx, "added", testCounter(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Undo(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	st := store.State{}
+	s.Select(&st)
+	if _, hasKey := st["added"]; hasKey || len(st) != 1 {
+		t.Error("The added Updater should have been removed, but the State is", st)
+	}
+}
+
+func TestHistoryCanBeLimited(t *testing.T) {
+	h, s := newTestStore(Limit(3))
+	defer s.Close()
+
+	dispatchAll(t, s, 1, 2, 3, 4)
+
+	sequences, cursor := h.Sequences()
+	if len(sequences) != 3 || sequences[0] != 2 || cursor != 2 {
+		t.Error("Only the last 3 states should have been recorded, but", sequences, "were")
+	}
+}
+
+func TestHistoryCanSkipActions(t *testing.T) {
+	isNotUndoable := func(action interface{}) bool {
+		return action == 100
+	}
+	h, s := newTestStore(NotUndoable(isNotUndoable))
+	defer s.Close()
+
+	dispatchAll(t, s, 1, 100)
+
+	if err := h.Undo(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if count := countOf(t, s); count != 0 {
+		t.Error("The action that is not undoable should be part of the previous step, but the count is", count)
+	}
+}
+
+func TestHistoryCanGroupActions(t *testing.T) {
+	groupTyping := func(action interface{}) interface{} {
+		if _, isTyping := action.(testTyping); isTyping {
+			return "typing"
+		}
+
+		return nil
+	}
+	h, s := newTestStore(GroupBy(groupTyping))
+	defer s.Close()
+
+	dispatchAll(t, s, 1, testTyping("a"), testTyping("bc"), testTyping("def"))
+
+	if err := h.Undo(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if count := countOf(t, s); count != 1 {
+		t.Error("The grouped actions should be undone together, but the count is", count)
+	}
+}
+
+func TestHistoryNotifiesSubscribersOfJumps(t *testing.T) {
+	h, s := newTestStore()
+	defer s.Close()
+
+	dispatchAll(t, s, 1)
+
+	changes := make(chan store.Change, 1)
+	if _, err := s.SubscribeChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Undo(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	change := <-changes
+	if jump, isJump := change.Action.(JumpAction); !isJump || jump.Steps != -1 {
+		t.Error("The subscriber should have been sent an undo JumpAction, but was sent", change.Action)
+	}
+}
+
+func TestHistoryMustBeApplied(t *testing.T) {
+	h := New()
+
+	if err := h.Undo(context.Background()); err != ErrNotApplied {
+		t.Error("Undoing before the history was applied should return ErrNotApplied, but returned", err)
+	}
+}
+
+func TestHistoryMovesTheCursorBeforeTheNextQueuedAction(t *testing.T) {
+	h := New()
+	s := store.New(store.State{"count": testCounter(0)}, h.Config, store.QueueCapacity(2))
+	defer s.Close()
+	ctx := context.Background()
+
+	dispatchAll(t, s, 1, 2)
+
+	// Both actions are queued before the Undo has been performed
+	undo := s.DispatchAsync(ctx, JumpAction{Steps: -1})
+	next := s.DispatchAsync(ctx, 10)
+	if err := undo.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := next.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	sequences, cursor := h.Sequences()
+	if len(sequences) != 3 || sequences[2] != next.Sequence() || cursor != 2 {
+		t.Error("The undone step should have been replaced by the queued action, but the sequences are", sequences, "with the cursor at", cursor)
+	}
+	if err := h.Redo(ctx); err != ErrNoEntry {
+		t.Error("Redo should not be possible after the queued action, but returned", err)
+	}
+	if count := countOf(t, s); count != 11 {
+		t.Error("The count should be 11, but is", count)
+	}
+}
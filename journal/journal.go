@@ -0,0 +1,445 @@
+package journal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/nheyn/go-redux/store"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrClosed is returned when a Journal is used after it has been closed.
+var ErrClosed = errors.New("journal: the journal is closed")
+
+// The file extension used for the segments of a Journal.
+const segmentExt = ".wal"
+
+// A CorruptionError is returned when a record in a Journal does not match its checksum, or can not be read.
+type CorruptionError struct {
+	// The path to the segment that has the corrupt record.
+	Segment string
+	// The offset of the corrupt record in the segment.
+	Offset int64
+	// The error that occurred while reading the record.
+	Err error
+}
+
+func (err *CorruptionError) Error() string {
+	return fmt.Sprintf("journal: corrupt record in %s at offset %d: %v", err.Segment, err.Offset, err.Err)
+}
+
+func (err *CorruptionError) Unwrap() error {
+	return err.Err
+}
+
+// A Journal is an append-only log of the actions performed by a Store. Each action is saved before the
+// State of the Store is updated, so the State can be rebuilt after a restart (see Recover(...)). The log
+// is split into segment files, named after the sequence of their first record.
+type Journal struct {
+	dir            string
+	registry       *ActionRegistry
+	syncEvery      int
+	maxSegmentSize int64
+
+	mu        sync.Mutex
+	segments  []segment
+	file      segmentFile
+	size      int64
+	unsynced  int
+	last      uint64
+	replaying bool
+	closed    bool
+
+	// The error from a write that could not be removed from the segment, nothing can be appended after it
+	failed error
+}
+
+// The file for the current segment.
+type segmentFile interface {
+	io.Writer
+	io.Seeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// A segment file in a Journal.
+type segment struct {
+	first uint64
+	path  string
+}
+
+// Opens the Journal in the given directory, creating it if needed. Any incomplete record at the end of
+// the last segment (i.e. from a crash during a write) is removed.
+func Open(dir string, registry *ActionRegistry, configs ...func(*Journal)) (*Journal, error) {
+	j := &Journal{
+		dir:            dir,
+		registry:       registry,
+		syncEvery:      1,
+		maxSegmentSize: 64 << 20,
+	}
+	for _, config := range configs {
+		config(j)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := j.loadSegments(); err != nil {
+		return nil, err
+	}
+	if len(j.segments) == 0 {
+		return j, nil
+	}
+
+	// Open the last segment for appending, after removing any incomplete record
+	lastSeg := j.segments[len(j.segments)-1]
+	last, validSize, err := j.scanSegment(lastSeg, true)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(lastSeg.path, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Truncate(validSize); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(validSize, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	j.file = file
+	j.size = validSize
+	j.last = last
+	if j.last == 0 {
+		j.last = lastSeg.first - 1
+	}
+
+	return j, nil
+}
+
+// A config function for a Journal that will sync the segment to disk after every action. This is the
+// default.
+func SyncAlways(j *Journal) {
+	j.syncEvery = 1
+}
+
+// A config function for a Journal that will sync the segment to disk after the given number of actions.
+func SyncEvery(actions int) func(*Journal) {
+	return func(j *Journal) {
+		j.syncEvery = actions
+	}
+}
+
+// A config function for a Journal that will never sync the segment, so the operating system will decide
+// when it is written to disk (except when the Journal is closed or a segment is rotated).
+func SyncNever(j *Journal) {
+	j.syncEvery = 0
+}
+
+// A config function for a Journal that will start a new segment once the current one is larger than the
+// given number of bytes.
+func MaxSegmentSize(size int64) func(*Journal) {
+	return func(j *Journal) {
+		j.maxSegmentSize = size
+	}
+}
+
+// Config is a config function for store.New(...), it will save each action to the Journal before the State
+// of the Store is updated. If the action can not be saved, the dispatch will return the error.
+func (j *Journal) Config(s *store.Store) {
+	store.BeforeCommit(func(change store.Change) error {
+		j.mu.Lock()
+		replaying := j.replaying
+		j.mu.Unlock()
+
+		if replaying {
+			return nil
+		}
+
+		return j.Append(Record{Sequence: change.Sequence, Action: change.Action, Partial: change.Partial != nil})
+	})(s)
+}
+
+// Append saves the given Record to the end of the Journal.
+func (j *Journal) Append(rec Record) error {
+	payload, err := j.registry.encode(rec)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return ErrClosed
+	}
+	if j.failed != nil {
+		return j.failed
+	}
+	if j.file == nil || j.size >= j.maxSegmentSize {
+		if err := j.rotate(rec.Sequence); err != nil {
+			return err
+		}
+	}
+
+	n, err := writeRecord(j.file, payload)
+	if err != nil {
+		if n > 0 {
+			return j.removePartialWrite(err)
+		}
+		return err
+	}
+	j.size += int64(n)
+	j.last = rec.Sequence
+
+	j.unsynced++
+	if j.syncEvery > 0 && j.unsynced >= j.syncEvery {
+		j.unsynced = 0
+		return j.file.Sync()
+	}
+
+	return nil
+}
+
+// Records returns all of the Records, in order, with a sequence after the given sequence.
+func (j *Journal) Records(after uint64) ([]Record, error) {
+	j.mu.Lock()
+	segments := append([]segment{}, j.segments...)
+	j.mu.Unlock()
+
+	records := []Record{}
+	for i, seg := range segments {
+		// Skip segments where every record is before the given sequence
+		if i+1 < len(segments) && segments[i+1].first <= after+1 {
+			continue
+		}
+
+		segRecords, err := j.readSegment(seg)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rec := range segRecords {
+			if rec.Sequence > after {
+				records = append(records, rec)
+			}
+		}
+	}
+
+	return records, nil
+}
+
+// LastSequence returns the sequence of the last Record in the Journal.
+func (j *Journal) LastSequence() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.last
+}
+
+// Compact removes the segments that only contain Records with a sequence up to the given sequence. It
+// should be called after a snapshot, at the given sequence, has been saved. The current segment is never
+// removed.
+func (j *Journal) Compact(upTo uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for len(j.segments) > 1 && j.segments[1].first <= upTo+1 {
+		if err := os.Remove(j.segments[0].path); err != nil {
+			return err
+		}
+
+		j.segments = j.segments[1:]
+	}
+
+	return nil
+}
+
+// Sync writes the current segment to disk.
+func (j *Journal) Sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	j.unsynced = 0
+	return j.file.Sync()
+}
+
+// Close syncs and closes the current segment, the Journal can not be used after it is closed.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return nil
+	}
+	j.closed = true
+
+	if j.file == nil {
+		return nil
+	}
+	if err := j.file.Sync(); err != nil {
+		j.file.Close()
+		return err
+	}
+
+	return j.file.Close()
+}
+
+// Sets if the Journal is replaying its Records, so they are not appended again.
+func (j *Journal) setReplaying(replaying bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.replaying = replaying
+}
+
+// Removes an incomplete record, from the given failed write, from the end of the current segment so the
+// next record is not written after it. If it can not be removed, the Journal can not be appended to. Must
+// be called with the lock held.
+func (j *Journal) removePartialWrite(writeErr error) error {
+	if err := j.file.Truncate(j.size); err != nil {
+		j.failed = fmt.Errorf("journal: unable to remove a partial record (%v): %w", writeErr, err)
+		return j.failed
+	}
+	if _, err := j.file.Seek(j.size, io.SeekStart); err != nil {
+		j.failed = fmt.Errorf("journal: unable to remove a partial record (%v): %w", writeErr, err)
+		return j.failed
+	}
+
+	return writeErr
+}
+
+// Closes the current segment, and starts a new one for the given sequence. Must be called with the lock
+// held.
+func (j *Journal) rotate(first uint64) error {
+	if j.file != nil {
+		if err := j.file.Sync(); err != nil {
+			return err
+		}
+		if err := j.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	seg := segment{first, filepath.Join(j.dir, fmt.Sprintf("%020d%s", first, segmentExt))}
+	file, err := os.OpenFile(seg.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	j.segments = append(j.segments, seg)
+	j.file = file
+	j.size = 0
+	j.unsynced = 0
+	return nil
+}
+
+// Finds all of the segments in the directory of the Journal.
+func (j *Journal) loadSegments() error {
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		first, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		j.segments = append(j.segments, segment{first, filepath.Join(j.dir, name)})
+	}
+
+	sort.Slice(j.segments, func(a, b int) bool {
+		return j.segments[a].first < j.segments[b].first
+	})
+	return nil
+}
+
+// Reads all of the Records in the given segment.
+func (j *Journal) readSegment(seg segment) ([]Record, error) {
+	records := []Record{}
+	err := j.forEachRecord(seg, false, func(rec Record, _ int64) {
+		records = append(records, rec)
+	})
+
+	return records, err
+}
+
+// Finds the sequence of the last Record in the given segment, and the size of the segment up to the end
+// of that Record. If allowTorn is true, an incomplete Record at the end of the segment is ignored.
+func (j *Journal) scanSegment(seg segment, allowTorn bool) (uint64, int64, error) {
+	var last uint64
+	var size int64
+	err := j.forEachRecord(seg, allowTorn, func(rec Record, end int64) {
+		last = rec.Sequence
+		size = end
+	})
+
+	return last, size, err
+}
+
+// Calls the given function with each Record in the given segment, along with the offset of the end of the
+// Record. Any Records that can not be read cause a *CorruptionError, unless allowTorn is true and it is an
+// incomplete Record at the end of the segment.
+func (j *Journal) forEachRecord(seg segment, allowTorn bool, fn func(Record, int64)) error {
+	file, err := os.Open(seg.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := &countingReader{r: bufio.NewReader(file)}
+	for {
+		offset := reader.count
+
+		payload, err := readRecord(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err == io.ErrUnexpectedEOF && allowTorn {
+			return nil
+		}
+		if err != nil {
+			return &CorruptionError{seg.path, offset, err}
+		}
+
+		rec, err := j.registry.decode(payload)
+		if err != nil {
+			return &CorruptionError{seg.path, offset, err}
+		}
+		fn(rec, reader.count)
+	}
+}
+
+// An io.Reader that counts the number of bytes read.
+type countingReader struct {
+	r     io.Reader
+	count int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.count += int64(n)
+
+	return n, err
+}
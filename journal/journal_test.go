@@ -0,0 +1,233 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"github.com/nheyn/go-redux/store"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func openTestJournal(t *testing.T, dir string, configs ...func(*Journal)) *Journal {
+	j, err := Open(dir, newTestRegistry(), configs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return j
+}
+
+func TestJournalSavesDispatchedActions(t *testing.T) {
+	j := openTestJournal(t, t.TempDir())
+	defer j.Close()
+
+	s := store.New(store.State{"COUNTER": testCounter(0)}, j.Config)
+	defer s.Close()
+
+	s.Dispatch(context.Background(), testAdd{1})
+	s.DispatchBatch(context.Background(), testAdd{2}, testAdd{3})
+
+	records, err := j.Records(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Action != (testAdd{1}) || records[1].Sequence != 2 {
+		t.Error("Both dispatches should have been saved, but got", records)
+	}
+	if j.LastSequence() != 2 {
+		t.Error("The last sequence should be 2, but was", j.LastSequence())
+	}
+
+	if records, _ := j.Records(1); len(records) != 1 || records[0].Sequence != 2 {
+		t.Error("Only the records after the given sequence should be returned, but got", records)
+	}
+}
+
+func TestJournalFailsTheDispatchIfTheActionCanNotBeSaved(t *testing.T) {
+	j := openTestJournal(t, t.TempDir())
+	defer j.Close()
+
+	s := store.New(store.State{"COUNTER": testCounter(0)}, j.Config)
+	defer s.Close()
+
+	if err := s.DispatchBatch(context.Background(), testAdd{1}, testUnregistered{}); err == nil {
+		t.Error("The dispatch should fail when the action can not be saved")
+	}
+	if records, _ := j.Records(0); len(records) != 0 {
+		t.Error("No records should have been saved, but got", records)
+	}
+}
+
+func TestJournalDoesNotSaveActionsThatChangeNothing(t *testing.T) {
+	j := openTestJournal(t, t.TempDir())
+	defer j.Close()
+
+	s := store.New(store.State{"COUNTER": testCounter(0)}, j.Config)
+	defer s.Close()
+
+	// The action does not change the counter, so it is not saved (even though it is not registered)
+	f := s.DispatchAsync(context.Background(), testUnregistered{})
+	if err := f.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if f.Sequence() != 0 {
+		t.Error("An action that changed nothing should not have a sequence, but has", f.Sequence())
+	}
+
+	if err := s.Dispatch(context.Background(), testAdd{1}); err != nil {
+		t.Fatal(err)
+	}
+	if records, _ := j.Records(0); len(records) != 1 || records[0].Sequence != 1 {
+		t.Error("Only the action that changed the counter should have been saved, but got", records)
+	}
+}
+
+func TestJournalRotatesAndCompactsSegments(t *testing.T) {
+	dir := t.TempDir()
+	j := openTestJournal(t, dir, MaxSegmentSize(1), SyncNever)
+	defer j.Close()
+
+	for i := uint64(1); i <= 3; i++ {
+		if err := j.Append(Record{Sequence: i, Action: testAdd{1}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if segments, _ := filepath.Glob(filepath.Join(dir, "*.wal")); len(segments) != 3 {
+		t.Error("Each record should be in its own segment, but there are", len(segments))
+	}
+
+	if err := j.Compact(2); err != nil {
+		t.Fatal(err)
+	}
+	if segments, _ := filepath.Glob(filepath.Join(dir, "*.wal")); len(segments) != 1 {
+		t.Error("The compacted segments should have been removed, but there are", len(segments))
+	}
+	if records, _ := j.Records(0); len(records) != 1 || records[0].Sequence != 3 {
+		t.Error("Only the last record should be left, but got", records)
+	}
+}
+
+func TestJournalRemovesATornRecordWhenOpened(t *testing.T) {
+	dir := t.TempDir()
+	j := openTestJournal(t, dir)
+	j.Append(Record{Sequence: 1, Action: testAdd{1}})
+	j.Append(Record{Sequence: 2, Action: testAdd{2}})
+	j.Close()
+
+	segment := filepath.Join(dir, "00000000000000000001.wal")
+	info, _ := os.Stat(segment)
+	if err := os.Truncate(segment, info.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+
+	j = openTestJournal(t, dir)
+	defer j.Close()
+
+	if j.LastSequence() != 1 {
+		t.Error("The torn record should have been removed, but the last sequence is", j.LastSequence())
+	}
+	if err := j.Append(Record{Sequence: 2, Action: testAdd{3}}); err != nil {
+		t.Fatal(err)
+	}
+	if records, err := j.Records(0); err != nil || len(records) != 2 || records[1].Action != (testAdd{3}) {
+		t.Error("The record should have been appended after the valid records, but got", records, err)
+	}
+}
+
+type testFailingFile struct {
+	segmentFile
+	failWrites   bool
+	failTruncate bool
+}
+
+func (f *testFailingFile) Write(p []byte) (int, error) {
+	if !f.failWrites {
+		return f.segmentFile.Write(p)
+	}
+
+	// Only part of the record is written, like a full disk
+	n, _ := f.segmentFile.Write(p[:len(p)/2])
+	return n, errors.New("test write error")
+}
+
+func (f *testFailingFile) Truncate(size int64) error {
+	if f.failTruncate {
+		return errors.New("test truncate error")
+	}
+
+	return f.segmentFile.Truncate(size)
+}
+
+func TestJournalRemovesAPartialRecordWhenAWriteFails(t *testing.T) {
+	dir := t.TempDir()
+	j := openTestJournal(t, dir)
+	if err := j.Append(Record{Sequence: 1, Action: testAdd{1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	failing := &testFailingFile{segmentFile: j.file, failWrites: true}
+	j.file = failing
+	if err := j.Append(Record{Sequence: 2, Action: testAdd{2}}); err == nil {
+		t.Fatal("The append should fail when the write fails")
+	}
+
+	failing.failWrites = false
+	if err := j.Append(Record{Sequence: 2, Action: testAdd{3}}); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	j = openTestJournal(t, dir)
+	defer j.Close()
+
+	if records, err := j.Records(0); err != nil || len(records) != 2 || records[1].Action != (testAdd{3}) {
+		t.Error("The partial record should have been removed, but got", records, err)
+	}
+}
+
+func TestJournalCanNotBeAppendedToIfAPartialRecordCanNotBeRemoved(t *testing.T) {
+	j := openTestJournal(t, t.TempDir())
+	defer j.Close()
+	if err := j.Append(Record{Sequence: 1, Action: testAdd{1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	j.file = &testFailingFile{segmentFile: j.file, failWrites: true, failTruncate: true}
+	if err := j.Append(Record{Sequence: 2, Action: testAdd{2}}); err == nil {
+		t.Fatal("The append should fail when the write fails")
+	}
+	if err := j.Append(Record{Sequence: 2, Action: testAdd{3}}); err == nil {
+		t.Error("Nothing should be appended after a partial record that could not be removed")
+	}
+}
+
+func TestJournalDetectsCorruptRecords(t *testing.T) {
+	dir := t.TempDir()
+	j := openTestJournal(t, dir)
+	j.Append(Record{Sequence: 1, Action: testAdd{1}})
+	j.Append(Record{Sequence: 2, Action: testAdd{2}})
+	defer j.Close()
+
+	segment := filepath.Join(dir, "00000000000000000001.wal")
+	data, _ := os.ReadFile(segment)
+	data[headerSize+2] ^= 0xff
+	os.WriteFile(segment, data, 0644)
+
+	_, err := j.Records(0)
+
+	var corruptErr *CorruptionError
+	if !errors.As(err, &corruptErr) || corruptErr.Offset != 0 || corruptErr.Segment != segment {
+		t.Error("A *CorruptionError should be returned for the first record, but got", err)
+	}
+}
+
+func TestJournalCanNotBeUsedAfterClose(t *testing.T) {
+	j := openTestJournal(t, t.TempDir())
+	j.Close()
+
+	if err := j.Append(Record{Sequence: 1, Action: testAdd{1}}); err != ErrClosed {
+		t.Error("ErrClosed should be returned after the journal is closed, but got", err)
+	}
+}
@@ -0,0 +1,164 @@
+package journal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nheyn/go-redux/store"
+	"hash/crc32"
+	"io"
+	"reflect"
+)
+
+// The size of the header, the length and checksum, before each record in a segment.
+const headerSize = 8
+
+// The largest record that can be read, any larger length is treated as corruption.
+const maxRecordSize = 1 << 28
+
+// The table used to create the checksum of each record.
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// The error returned when a record does not match its checksum.
+var errChecksum = errors.New("journal: record checksum mismatch")
+
+// A Record is an action, or store.Batch of actions, that was saved in a Journal.
+type Record struct {
+	// The sequence of the Change caused by the action, see store.Change.Sequence.
+	Sequence uint64
+	// The action that was dispatched, for a batch this is a store.Batch.
+	Action interface{}
+	// If the action was partially committed, so some of the Updaters failed (see store.PartialCommit(...)).
+	Partial bool
+}
+
+// An ActionRegistry contains the action types that can be saved in a Journal. Every action that is
+// dispatched to a Store using a Journal must be registered.
+type ActionRegistry struct {
+	byType map[reflect.Type]string
+	byName map[string]reflect.Type
+}
+
+// Creates an empty ActionRegistry.
+func NewActionRegistry() *ActionRegistry {
+	return &ActionRegistry{
+		byType: map[reflect.Type]string{},
+		byName: map[string]reflect.Type{},
+	}
+}
+
+// Register adds the type of the given example action to the registry, it is saved in the Journal using
+// the given name. The action is encoded using encoding/json.
+func (r *ActionRegistry) Register(name string, example interface{}) {
+	typ := reflect.TypeOf(example)
+	r.byType[typ] = name
+	r.byName[name] = typ
+}
+
+// The serialized version of a Record.
+type encodedRecord struct {
+	Sequence uint64          `json:"seq"`
+	Batch    bool            `json:"batch,omitempty"`
+	Partial  bool            `json:"partial,omitempty"`
+	Actions  []encodedAction `json:"actions"`
+}
+
+// The serialized version of an action.
+type encodedAction struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Serializes the given Record, using the registered action types.
+func (r *ActionRegistry) encode(rec Record) ([]byte, error) {
+	batch, isBatch := rec.Action.(store.Batch)
+	if !isBatch {
+		batch = store.Batch{rec.Action}
+	}
+
+	encoded := encodedRecord{Sequence: rec.Sequence, Batch: isBatch, Partial: rec.Partial}
+	for _, action := range batch {
+		name, isRegistered := r.byType[reflect.TypeOf(action)]
+		if !isRegistered {
+			return nil, fmt.Errorf("journal: the action type %T has not been registered", action)
+		}
+
+		data, err := json.Marshal(action)
+		if err != nil {
+			return nil, err
+		}
+		encoded.Actions = append(encoded.Actions, encodedAction{name, data})
+	}
+
+	return json.Marshal(encoded)
+}
+
+// Creates a Record from the given serialized version, using the registered action types.
+func (r *ActionRegistry) decode(payload []byte) (Record, error) {
+	var encoded encodedRecord
+	if err := json.Unmarshal(payload, &encoded); err != nil {
+		return Record{}, err
+	}
+
+	batch := store.Batch{}
+	for _, encodedAction := range encoded.Actions {
+		typ, isRegistered := r.byName[encodedAction.Type]
+		if !isRegistered {
+			return Record{}, fmt.Errorf("journal: the action type %s has not been registered", encodedAction.Type)
+		}
+
+		ptr := reflect.New(typ)
+		if err := json.Unmarshal(encodedAction.Data, ptr.Interface()); err != nil {
+			return Record{}, err
+		}
+		batch = append(batch, ptr.Elem().Interface())
+	}
+
+	if encoded.Batch {
+		return Record{Sequence: encoded.Sequence, Action: batch, Partial: encoded.Partial}, nil
+	}
+	if len(batch) != 1 {
+		return Record{}, fmt.Errorf("journal: record %d has %d actions", encoded.Sequence, len(batch))
+	}
+
+	return Record{Sequence: encoded.Sequence, Action: batch[0], Partial: encoded.Partial}, nil
+}
+
+// Writes the given payload, after a header with its length and checksum.
+func writeRecord(w io.Writer, payload []byte) (int, error) {
+	buf := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.Checksum(payload, crcTable))
+	copy(buf[headerSize:], payload)
+
+	return w.Write(buf)
+}
+
+// Reads the next payload, and checks it against its checksum. Returns io.EOF if there are no more records,
+// or io.ErrUnexpectedEOF if the last record is incomplete.
+func readRecord(r io.Reader) ([]byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[0:4])
+	if size > maxRecordSize {
+		return nil, errChecksum
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, errChecksum
+	}
+
+	return payload, nil
+}
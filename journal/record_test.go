@@ -0,0 +1,103 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"github.com/nheyn/go-redux/store"
+	"io"
+	"testing"
+)
+
+type testCounter int
+
+func (c testCounter) Update(_ context.Context, action interface{}) (store.Updater, error) {
+	if add, isAdd := action.(testAdd); isAdd {
+		return c + testCounter(add.Amount), nil
+	}
+
+	return c, nil
+}
+
+type testAdd struct {
+	Amount int
+}
+
+type testUnregistered struct{}
+
+func newTestRegistry() *ActionRegistry {
+	registry := NewActionRegistry()
+	registry.Register("add", testAdd{})
+
+	return registry
+}
+
+func TestRegistryCanRoundTripRecords(t *testing.T) {
+	registry := newTestRegistry()
+	records := []Record{
+		{Sequence: 1, Action: testAdd{5}},
+		{Sequence: 2, Action: store.Batch{testAdd{1}, testAdd{2}}},
+		{Sequence: 3, Action: testAdd{1}, Partial: true},
+	}
+
+	for _, rec := range records {
+		payload, err := registry.encode(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := registry.decode(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Sequence != rec.Sequence {
+			t.Error("The sequence should be", rec.Sequence, ", but was", decoded.Sequence)
+		}
+		if decoded.Partial != rec.Partial {
+			t.Error("The partial flag should be", rec.Partial, ", but was", decoded.Partial)
+		}
+		if batch, isBatch := rec.Action.(store.Batch); isBatch {
+			decodedBatch, _ := decoded.Action.(store.Batch)
+			if len(decodedBatch) != len(batch) || decodedBatch[1] != batch[1] {
+				t.Error("The batch should have been decoded, but was", decoded.Action)
+			}
+		} else if decoded.Action != rec.Action {
+			t.Error("The action should be", rec.Action, ", but was", decoded.Action)
+		}
+	}
+}
+
+func TestRegistryRejectsUnregisteredActions(t *testing.T) {
+	if _, err := newTestRegistry().encode(Record{Sequence: 1, Action: testUnregistered{}}); err == nil {
+		t.Error("An unregistered action should not be encoded")
+	}
+}
+
+func TestReadRecordChecksThePayload(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writeRecord(buf, []byte("first"))
+	writeRecord(buf, []byte("second"))
+
+	data := buf.Bytes()
+	r := bytes.NewReader(data)
+	if payload, err := readRecord(r); err != nil || string(payload) != "first" {
+		t.Error("The first record should be read, but got", string(payload), err)
+	}
+	if payload, err := readRecord(r); err != nil || string(payload) != "second" {
+		t.Error("The second record should be read, but got", string(payload), err)
+	}
+	if _, err := readRecord(r); err != io.EOF {
+		t.Error("io.EOF should be returned after the last record, but got", err)
+	}
+
+	torn := bytes.NewReader(data[:len(data)-2])
+	readRecord(torn)
+	if _, err := readRecord(torn); err != io.ErrUnexpectedEOF {
+		t.Error("io.ErrUnexpectedEOF should be returned for an incomplete record, but got", err)
+	}
+
+	corrupt := append([]byte{}, data...)
+	corrupt[headerSize] ^= 0xff
+	if _, err := readRecord(bytes.NewReader(corrupt)); err != errChecksum {
+		t.Error("A checksum error should be returned for a changed record, but got", err)
+	}
+}
@@ -0,0 +1,77 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"github.com/nheyn/go-redux/snapshot"
+	"github.com/nheyn/go-redux/store"
+	"sync/atomic"
+)
+
+// Recover creates a Store from the latest snapshot, and then replays the Records in the given Journal
+// that were saved after it. The Records that were partially committed are replayed using
+// store.WithPartialCommit(...). The Store is created with the given config functions, and is configured to
+// save any new actions to the Journal. If the snapshot registry or storage is nil, all of the Records are
+// replayed on the given initial State.
+func Recover(
+	ctx context.Context,
+	j *Journal,
+	registry *snapshot.Registry,
+	storage snapshot.Storage,
+	initialState store.State,
+	configs ...func(*store.Store),
+) (*store.Store, error) {
+	st := initialState
+	var sequence uint64
+	if registry != nil && storage != nil {
+		var err error
+		st, sequence, err = snapshot.Load(registry, storage, initialState)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	records, err := j.Records(sequence)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 && records[0].Sequence != sequence+1 {
+		return nil, fmt.Errorf(
+			"journal: the snapshot is at sequence %d, but the first record is %d",
+			sequence, records[0].Sequence,
+		)
+	}
+
+	// Track the sequence of each replayed action, so it can be checked against the record
+	var replayed uint64
+	trackSequence := store.OnCommit(func(change store.Change) {
+		atomic.StoreUint64(&replayed, change.Sequence)
+	})
+
+	j.setReplaying(true)
+	defer j.setReplaying(false)
+
+	s := store.New(st, append(configs, store.StartSequence(sequence), trackSequence, j.Config)...)
+	for _, rec := range records {
+		var err error
+		if batch, isBatch := rec.Action.(store.Batch); isBatch {
+			err = s.DispatchBatch(ctx, batch...)
+		} else if rec.Partial {
+			err = s.Dispatch(store.WithPartialCommit(ctx), rec.Action)
+		} else {
+			err = s.Dispatch(ctx, rec.Action)
+		}
+
+		// A partial commit still updates the State, so it is replayed like any other Record
+		if err != nil && !(rec.Partial && store.IsPartialCommit(err)) {
+			s.Close()
+			return nil, fmt.Errorf("journal: unable to replay record %d: %w", rec.Sequence, err)
+		}
+		if seq := atomic.LoadUint64(&replayed); seq != rec.Sequence {
+			s.Close()
+			return nil, fmt.Errorf("journal: record %d was replayed as %d", rec.Sequence, seq)
+		}
+	}
+
+	return s, nil
+}
@@ -0,0 +1,141 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"github.com/nheyn/go-redux/snapshot"
+	"github.com/nheyn/go-redux/store"
+	"path/filepath"
+	"testing"
+)
+
+type counterSelector struct {
+	value testCounter
+}
+
+func (sel *counterSelector) SelectFrom(st *store.State) {
+	sel.value, _ = (*st)["COUNTER"].(testCounter)
+}
+
+func selectCounter(t *testing.T, s *store.Store) testCounter {
+	sel := &counterSelector{}
+	if err := s.Select(sel); err != nil {
+		t.Fatal(err)
+	}
+
+	return sel.value
+}
+
+func TestRecoverReplaysTheJournal(t *testing.T) {
+	dir := t.TempDir()
+	initialState := store.State{"COUNTER": testCounter(0)}
+
+	j := openTestJournal(t, dir)
+	s := store.New(initialState, j.Config)
+	s.Dispatch(context.Background(), testAdd{1})
+	s.DispatchBatch(context.Background(), testAdd{2}, testAdd{3})
+	s.Close()
+	j.Close()
+
+	j = openTestJournal(t, dir)
+	defer j.Close()
+
+	s, err := Recover(context.Background(), j, nil, nil, initialState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if counter := selectCounter(t, s); counter != 6 {
+		t.Error("The state should have been rebuilt from the journal, but the counter is", counter)
+	}
+	if records, _ := j.Records(0); len(records) != 2 {
+		t.Error("The replayed actions should not be saved again, but there are", len(records), "records")
+	}
+
+	s.Dispatch(context.Background(), testAdd{4})
+	if records, _ := j.Records(0); len(records) != 3 || records[2].Sequence != 3 {
+		t.Error("New actions should be saved after recovering, but got", records)
+	}
+}
+
+type testFailingUpdater struct{}
+
+func (testFailingUpdater) Update(_ context.Context, _ interface{}) (store.Updater, error) {
+	return nil, errors.New("test updater error")
+}
+
+func TestRecoverReplaysPartialCommits(t *testing.T) {
+	dir := t.TempDir()
+	initialState := store.State{"COUNTER": testCounter(0), "FAILING": testFailingUpdater{}}
+
+	j := openTestJournal(t, dir)
+	s := store.New(initialState, j.Config, store.PartialCommit)
+	if err := s.Dispatch(context.Background(), testAdd{1}); !store.IsPartialCommit(err) {
+		t.Fatal("The dispatch should have been partially committed, but got", err)
+	}
+	s.Close()
+	j.Close()
+
+	j = openTestJournal(t, dir)
+	defer j.Close()
+
+	if records, _ := j.Records(0); len(records) != 1 || !records[0].Partial {
+		t.Fatal("The partial commit should have been saved, but got", records)
+	}
+
+	// The recovered Store does not use partial commits for every dispatch
+	s, err := Recover(context.Background(), j, nil, nil, initialState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if counter := selectCounter(t, s); counter != 1 {
+		t.Error("The partial commit should have been replayed, but the counter is", counter)
+	}
+}
+
+func TestRecoverStartsFromTheSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	initialState := store.State{"COUNTER": testCounter(0)}
+
+	registry := snapshot.NewRegistry(snapshot.JSON)
+	registry.Register("counter", "COUNTER", testCounter(0))
+	storage := &snapshot.FileStorage{Path: filepath.Join(dir, "snapshot.json")}
+	p := snapshot.NewPersister(registry, storage, snapshot.Every(2))
+
+	j := openTestJournal(t, filepath.Join(dir, "journal"), MaxSegmentSize(1))
+	s := store.New(initialState, p.Config, j.Config)
+	for i := 1; i <= 3; i++ {
+		s.Dispatch(context.Background(), testAdd{i})
+	}
+	s.Close()
+	j.Compact(2)
+	j.Close()
+
+	j = openTestJournal(t, filepath.Join(dir, "journal"))
+	defer j.Close()
+
+	s, err := Recover(context.Background(), j, registry, storage, initialState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if counter := selectCounter(t, s); counter != 6 {
+		t.Error("The state should have been rebuilt from the snapshot and journal, but the counter is", counter)
+	}
+}
+
+func TestRecoverFailsIfTheJournalHasAGap(t *testing.T) {
+	dir := t.TempDir()
+	j := openTestJournal(t, dir)
+	defer j.Close()
+
+	j.Append(Record{Sequence: 2, Action: testAdd{1}})
+
+	if _, err := Recover(context.Background(), j, nil, nil, store.State{"COUNTER": testCounter(0)}); err == nil {
+		t.Error("Recover should fail when the journal is missing records")
+	}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+)
+
+// The type of a metric Family.
+type Type string
+
+const (
+	Counter   Type = "counter"
+	Gauge     Type = "gauge"
+	Histogram Type = "histogram"
+)
+
+// A Collector gathers the current value of its metrics, so they can be written by WriteText(...).
+type Collector interface {
+	// Collect returns all of the metric Families, it must be safe to call from multiple goroutines.
+	Collect() []Family
+}
+
+// A Family is a group of Samples with the same name and type.
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// A Sample is a single value of a metric.
+type Sample struct {
+	// Added to the name of the Family (i.e. "_bucket" for a histogram bucket).
+	Suffix string
+	Labels Labels
+	Value  float64
+}
+
+// Labels are the names and values that identify a Sample in a Family.
+type Labels map[string]string
+
+// Creates a key, for the given label values, that can be used in a map.
+func labelKey(values ...string) string {
+	return strings.Join(values, "\xff")
+}
+
+// Gets the names of the labels in sorted order.
+func (labels Labels) names() []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
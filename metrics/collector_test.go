@@ -0,0 +1,17 @@
+package metrics
+
+import "testing"
+
+func TestLabelNamesAreSorted(t *testing.T) {
+	names := Labels{"type": "a", "outcome": "ok", "key": "b"}.names()
+
+	if len(names) != 3 || names[0] != "key" || names[1] != "outcome" || names[2] != "type" {
+		t.Error("labels names should be sorted: ", names)
+	}
+}
+
+func TestLabelKeysAreUnique(t *testing.T) {
+	if labelKey("a", "bc") == labelKey("ab", "c") {
+		t.Error("label keys should be different for different values")
+	}
+}
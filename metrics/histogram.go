@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"math"
+	"strconv"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets used for the latency histograms.
+var DefaultBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// A histogram counts observations in buckets, for each set of label values.
+type histogram struct {
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+// The observations for a single set of label values.
+type histogramSeries struct {
+	labels Labels
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Creates a histogram with the given bucket upper bounds.
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{
+		buckets: buckets,
+		series:  map[string]*histogramSeries{},
+	}
+}
+
+// Adds the given value to the series for the given labels.
+func (h *histogram) observe(labels Labels, value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := labelKey(labelValues(labels)...)
+	series, hasSeries := h.series[key]
+	if !hasSeries {
+		series = &histogramSeries{labels: labels, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+
+	for i, upperBound := range h.buckets {
+		if value <= upperBound {
+			series.counts[i]++
+		}
+	}
+	series.count++
+	series.sum += value
+}
+
+// Creates the Samples for all of the series, the buckets are cumulative.
+func (h *histogram) samples() []Sample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	samples := []Sample{}
+	for _, series := range h.series {
+		for i, upperBound := range h.buckets {
+			samples = append(samples, Sample{"_bucket", withLabel(series.labels, "le", formatFloat(upperBound)), float64(series.counts[i])})
+		}
+
+		samples = append(samples,
+			Sample{"_bucket", withLabel(series.labels, "le", "+Inf"), float64(series.count)},
+			Sample{"_sum", series.labels, series.sum},
+			Sample{"_count", series.labels, float64(series.count)},
+		)
+	}
+
+	return samples
+}
+
+// Gets the values of the given labels, in the order of their names.
+func labelValues(labels Labels) []string {
+	values := []string{}
+	for _, name := range labels.names() {
+		values = append(values, name, labels[name])
+	}
+
+	return values
+}
+
+// Creates a copy of the given labels, with the given label added.
+func withLabel(labels Labels, name, value string) Labels {
+	copied := make(Labels, len(labels)+1)
+	for n, v := range labels {
+		copied[n] = v
+	}
+	copied[name] = value
+
+	return copied
+}
+
+// Formats the given value for the text exposition format.
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHistogramBucketsAreCumulative(t *testing.T) {
+	h := newHistogram([]float64{1, 2})
+	h.observe(Labels{"key": "a"}, 0.5)
+	h.observe(Labels{"key": "a"}, 1.5)
+	h.observe(Labels{"key": "a"}, 3)
+
+	expected := map[string]float64{
+		"_bucket 1":    1,
+		"_bucket 2":    2,
+		"_bucket +Inf": 3,
+		"_sum ":        5,
+		"_count ":      3,
+	}
+	samples := h.samples()
+	if len(samples) != len(expected) {
+		t.Fatal("histogram should have a sample for each bucket, the sum and the count: ", samples)
+	}
+	for _, sample := range samples {
+		if sample.Labels["key"] != "a" {
+			t.Error("histogram samples should keep the labels: ", sample.Labels)
+		}
+
+		name := sample.Suffix + " " + sample.Labels["le"]
+		if value, hasValue := expected[name]; !hasValue || value != sample.Value {
+			t.Error("invalid histogram sample ", name, ": ", sample.Value)
+		}
+	}
+}
+
+func TestHistogramHasSeriesForEachLabel(t *testing.T) {
+	h := newHistogram([]float64{1})
+	h.observe(Labels{"key": "a"}, 0.5)
+	h.observe(Labels{"key": "b"}, 0.5)
+
+	counts := map[string]float64{}
+	for _, sample := range h.samples() {
+		if sample.Suffix == "_count" {
+			counts[sample.Labels["key"]] = sample.Value
+		}
+	}
+
+	if len(counts) != 2 || counts["a"] != 1 || counts["b"] != 1 {
+		t.Error("histogram should count each label separately: ", counts)
+	}
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := map[float64]string{
+		1:            "1",
+		0.005:        "0.005",
+		1e-7:         "1e-07",
+		math.Inf(1):  "+Inf",
+		math.Inf(-1): "-Inf",
+	}
+
+	for value, expected := range tests {
+		if formatted := formatFloat(value); formatted != expected {
+			t.Error("invalid formatted float for ", value, ": ", formatted)
+		}
+	}
+	if formatted := formatFloat(math.NaN()); formatted != "NaN" {
+		t.Error("invalid formatted float for NaN: ", formatted)
+	}
+}
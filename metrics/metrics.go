@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"github.com/nheyn/go-redux/middleware"
+	"github.com/nheyn/go-redux/store"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StoreMetrics records the throughput of a Store, it is a Collector so it can be passed to Handler(...).
+// Ex)
+//	m := metrics.New(metrics.Namespace("todos"))
+//	s := store.New(initialState, m.Config)
+//
+//	http.Handle("/metrics", metrics.Handler(m))
+type StoreMetrics struct {
+	namespace string
+
+	mu      sync.Mutex
+	store   *store.Store
+	actions map[string]*actionCount
+
+	dispatchDuration *histogram
+	updateDuration   *histogram
+}
+
+// The number of actions of a type, with an outcome.
+type actionCount struct {
+	actionType string
+	outcome    string
+	count      uint64
+}
+
+// Creates a new StoreMetrics.
+func New(configs ...func(*StoreMetrics)) *StoreMetrics {
+	m := &StoreMetrics{
+		namespace:        "redux",
+		actions:          map[string]*actionCount{},
+		dispatchDuration: newHistogram(DefaultBuckets),
+		updateDuration:   newHistogram(DefaultBuckets),
+	}
+	for _, config := range configs {
+		config(m)
+	}
+
+	return m
+}
+
+// A config function for New(...) that sets the prefix of the metric names, the default is "redux".
+func Namespace(namespace string) func(*StoreMetrics) {
+	return func(m *StoreMetrics) {
+		m.namespace = namespace
+	}
+}
+
+// A config function for New(...) that sets the upper bounds, in seconds, of the latency histogram
+// buckets, the default is DefaultBuckets. The bounds do not need to be in order, and a +Inf bucket is
+// always added.
+func Buckets(buckets ...float64) func(*StoreMetrics) {
+	bounds := []float64{}
+	for _, upperBound := range buckets {
+		if !math.IsNaN(upperBound) && !math.IsInf(upperBound, 1) {
+			bounds = append(bounds, upperBound)
+		}
+	}
+	sort.Float64s(bounds)
+
+	sorted := []float64{}
+	for i, upperBound := range bounds {
+		if i == 0 || upperBound != bounds[i-1] {
+			sorted = append(sorted, upperBound)
+		}
+	}
+
+	return func(m *StoreMetrics) {
+		m.dispatchDuration = newHistogram(sorted)
+		m.updateDuration = newHistogram(sorted)
+	}
+}
+
+// Config is a config function for store.New(...), it will add a middleware that records each dispatched
+// action and a hook that records each call to an Updater. Only one Store should be added to a StoreMetrics.
+func (m *StoreMetrics) Config(s *store.Store) {
+	m.mu.Lock()
+	m.store = s
+	m.mu.Unlock()
+
+	middleware.Apply(m.middleware)(s)
+	store.OnUpdate(m.recordUpdate)(s)
+}
+
+// Collect returns the current metrics for the Store.
+func (m *StoreMetrics) Collect() []Family {
+	m.mu.Lock()
+	s := m.store
+	actionSamples := []Sample{}
+	for _, c := range m.actions {
+		actionSamples = append(actionSamples, Sample{Labels: Labels{"type": c.actionType, "outcome": c.outcome}, Value: float64(c.count)})
+	}
+	m.mu.Unlock()
+
+	families := []Family{
+		{m.name("actions_total"), "The number of dispatched actions, by type and outcome.", Counter, actionSamples},
+		{m.name("dispatch_duration_seconds"), "How long it took to dispatch an action.", Histogram, m.dispatchDuration.samples()},
+		{m.name("update_duration_seconds"), "How long it took an Updater to handle an action.", Histogram, m.updateDuration.samples()},
+	}
+
+	// The Store has not been created yet, or it is closed
+	if s == nil {
+		return families
+	}
+	stats, err := s.Stats()
+	if err != nil {
+		return families
+	}
+
+	return append(families,
+		m.gauge("queued_actions", "The number of actions waiting to be dispatched.", float64(stats.QueuedActions)),
+		m.gauge("queue_capacity", "The number of actions that can wait to be dispatched.", float64(stats.QueueCapacity)),
+		m.gauge("subscribers", "The number of subscribers.", float64(stats.Subscribers)),
+		m.gauge("subscriber_backlog", "The number of updates that have not been delivered to the subscribers.", float64(stats.SubscriberBacklog)),
+		Family{m.name("dropped_updates_total"), "The number of updates that were dropped for the subscribers.", Counter, []Sample{{Value: float64(stats.DroppedUpdates)}}},
+	)
+}
+
+// The middleware that records the outcome and duration of each action.
+func (m *StoreMetrics) middleware(_ *store.Store) middleware.Func {
+	return func(ctx context.Context, action interface{}, next middleware.Next) error {
+		start := time.Now()
+		err := next(ctx, action)
+		duration := time.Since(start)
+
+		actionType := fmt.Sprintf("%T", action)
+		outcome := "ok"
+		if err != nil {
+			outcome = "error"
+		}
+
+		m.mu.Lock()
+		key := labelKey(actionType, outcome)
+		c, hasCount := m.actions[key]
+		if !hasCount {
+			c = &actionCount{actionType: actionType, outcome: outcome}
+			m.actions[key] = c
+		}
+		c.count++
+		m.mu.Unlock()
+
+		m.dispatchDuration.observe(Labels{"type": actionType}, duration.Seconds())
+		return err
+	}
+}
+
+// Records the duration of a call to an Updater.
+func (m *StoreMetrics) recordUpdate(info store.UpdateInfo) {
+	m.updateDuration.observe(Labels{"key": fmt.Sprint(info.Key)}, info.Duration.Seconds())
+}
+
+// Creates a Family with a single gauge Sample.
+func (m *StoreMetrics) gauge(name, help string, value float64) Family {
+	return Family{m.name(name), help, Gauge, []Sample{{Value: value}}}
+}
+
+// Adds the namespace to the given metric name.
+func (m *StoreMetrics) name(name string) string {
+	if m.namespace == "" {
+		return name
+	}
+
+	return m.namespace + "_" + name
+}
@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/nheyn/go-redux/store"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testCounter int
+
+func (c testCounter) Update(_ context.Context, action interface{}) (store.Updater, error) {
+	switch action.(type) {
+	case testIncrement:
+		return c + 1, nil
+	case testFail:
+		return nil, errors.New("failed")
+	}
+
+	return c, nil
+}
+
+type testIncrement struct{}
+
+type testFail struct{}
+
+func collectText(t *testing.T, m *StoreMetrics) string {
+	buf := &bytes.Buffer{}
+	if err := WriteText(buf, m); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+func TestActionsAreCountedByTypeAndOutcome(t *testing.T) {
+	m := New()
+	s := store.New(store.State{"count": testCounter(0)}, m.Config)
+	defer s.Close()
+
+	s.Dispatch(context.Background(), testIncrement{})
+	s.Dispatch(context.Background(), testIncrement{})
+	s.Dispatch(context.Background(), testFail{})
+
+	text := collectText(t, m)
+	if !strings.Contains(text, `redux_actions_total{outcome="ok",type="metrics.testIncrement"} 2`) {
+		t.Error("successful actions should be counted: ", text)
+	}
+	if !strings.Contains(text, `redux_actions_total{outcome="error",type="metrics.testFail"} 1`) {
+		t.Error("failed actions should be counted: ", text)
+	}
+	if !strings.Contains(text, `redux_dispatch_duration_seconds_count{type="metrics.testIncrement"} 2`) {
+		t.Error("dispatch durations should be recorded: ", text)
+	}
+}
+
+func TestUpdateDurationsAreRecordedByKey(t *testing.T) {
+	m := New()
+	s := store.New(store.State{"count": testCounter(0)}, m.Config)
+	defer s.Close()
+
+	s.Dispatch(context.Background(), testIncrement{})
+
+	text := collectText(t, m)
+	if !strings.Contains(text, `redux_update_duration_seconds_count{key="count"} 1`) {
+		t.Error("update durations should be recorded: ", text)
+	}
+	if !strings.Contains(text, `redux_update_duration_seconds_bucket{key="count",le="+Inf"} 1`) {
+		t.Error("update durations should have a +Inf bucket: ", text)
+	}
+}
+
+func TestStoreStatsAreGauges(t *testing.T) {
+	m := New(Namespace("test"))
+	s := store.New(store.State{"count": testCounter(0)}, m.Config, store.QueueCapacity(8))
+	defer s.Close()
+
+	unsubscribe, err := s.Subscribe(make(chan *store.Store, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	text := collectText(t, m)
+	for _, line := range []string{"test_queue_capacity 8", "test_subscribers 1", "# TYPE test_dropped_updates_total counter"} {
+		if !strings.Contains(text, line) {
+			t.Error("missing metric ", line, ": ", text)
+		}
+	}
+}
+
+func TestClosedStoreOnlyHasCounters(t *testing.T) {
+	m := New()
+	s := store.New(store.State{"count": testCounter(0)}, m.Config)
+	s.Dispatch(context.Background(), testIncrement{})
+	s.Close()
+
+	text := collectText(t, m)
+	if strings.Contains(text, "redux_subscribers") {
+		t.Error("closed stores should not have stats: ", text)
+	}
+	if !strings.Contains(text, "redux_actions_total") {
+		t.Error("closed stores should still have counters: ", text)
+	}
+}
+
+func TestCustomBuckets(t *testing.T) {
+	m := New(Buckets(60))
+	s := store.New(store.State{"count": testCounter(0)}, m.Config)
+	defer s.Close()
+
+	s.Dispatch(context.Background(), testIncrement{})
+
+	text := collectText(t, m)
+	if !strings.Contains(text, `redux_dispatch_duration_seconds_bucket{le="60",type="metrics.testIncrement"} 1`) {
+		t.Error("custom buckets should be used: ", text)
+	}
+}
+
+func TestBucketsAreSortedWithoutDuplicates(t *testing.T) {
+	m := New(Buckets(5, 1, 5))
+	s := store.New(store.State{"count": testCounter(0)}, m.Config)
+	defer s.Close()
+
+	s.Dispatch(context.Background(), testIncrement{})
+
+	text := collectText(t, m)
+	expected := `redux_dispatch_duration_seconds_bucket{le="1",type="metrics.testIncrement"} 1
+redux_dispatch_duration_seconds_bucket{le="5",type="metrics.testIncrement"} 1
+redux_dispatch_duration_seconds_bucket{le="+Inf",type="metrics.testIncrement"} 1
+redux_dispatch_duration_seconds_sum{type="metrics.testIncrement"} `
+	if !strings.Contains(text, expected) {
+		t.Error("the buckets should be sorted without duplicates: ", text)
+	}
+}
+
+func TestDroppedUpdatesIncludeRemovedSubscribers(t *testing.T) {
+	m := New()
+	s := store.New(store.State{"count": testCounter(0)}, m.Config)
+	defer s.Close()
+
+	sub := make(chan *store.Store)
+	subscription, err := s.SubscribeWith(sub, store.Buffered(1), store.DropNewest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		s.Dispatch(context.Background(), testIncrement{})
+	}
+	subscription.Unsubscribe()
+	dropped := subscription.Dropped()
+	if dropped == 0 {
+		t.Fatal("the subscriber should have dropped updates")
+	}
+
+	text := collectText(t, m)
+	if expected := "redux_dropped_updates_total " + strconv.FormatUint(dropped, 10); !strings.Contains(text, expected) {
+		t.Error("the dropped updates should still be counted after unsubscribing, expected ", expected, ": ", text)
+	}
+}
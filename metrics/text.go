@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The content type of the text exposition format.
+const textContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText writes the metrics from the given Collectors using the Prometheus text exposition format.
+func WriteText(w io.Writer, collectors ...Collector) error {
+	buf := bufio.NewWriter(w)
+	for _, collector := range collectors {
+		for _, family := range collector.Collect() {
+			writeFamily(buf, family)
+		}
+	}
+
+	return buf.Flush()
+}
+
+// Handler creates an http.Handler that writes the metrics from the given Collectors, so they can be
+// scraped.
+// Ex)
+//	m := metrics.New()
+//	s := store.New(initialState, m.Config)
+//
+//	http.Handle("/metrics", metrics.Handler(m))
+func Handler(collectors ...Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", textContentType)
+
+		if err := WriteText(w, collectors...); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// Writes the given Family, with its Samples grouped by series (their labels, other than "le") and the
+// series sorted by their labels. The buckets of a histogram series are in order, followed by the _sum
+// and _count.
+func writeFamily(w *bufio.Writer, family Family) {
+	if family.Help != "" {
+		w.WriteString("# HELP " + family.Name + " " + escapeHelp(family.Help) + "\n")
+	}
+	w.WriteString("# TYPE " + family.Name + " " + string(family.Type) + "\n")
+
+	samples := append([]Sample{}, family.Samples...)
+	sort.SliceStable(samples, func(i, j int) bool {
+		return lessSample(samples[i], samples[j])
+	})
+	for _, sample := range samples {
+		w.WriteString(family.Name + sample.Suffix + formatLabels(sample.Labels) + " " + formatFloat(sample.Value) + "\n")
+	}
+}
+
+// Checks if the first Sample should be written before the second.
+func lessSample(a, b Sample) bool {
+	if seriesA, seriesB := seriesOf(a), seriesOf(b); seriesA != seriesB {
+		return seriesA < seriesB
+	}
+	if a.Suffix != b.Suffix {
+		return suffixOrder(a.Suffix) < suffixOrder(b.Suffix)
+	}
+
+	return upperBoundOf(a) < upperBoundOf(b)
+}
+
+// Formats the labels of the series the given Sample is in, which are all of its labels except "le".
+func seriesOf(sample Sample) string {
+	labels := Labels{}
+	for name, value := range sample.Labels {
+		if name != "le" {
+			labels[name] = value
+		}
+	}
+
+	return formatLabels(labels)
+}
+
+// Gets the position of the given suffix within a series.
+func suffixOrder(suffix string) int {
+	switch suffix {
+	case "_sum":
+		return 1
+	case "_count":
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Gets the upper bound of a bucket Sample, "+Inf" is parsed as the largest value.
+func upperBoundOf(sample Sample) float64 {
+	upperBound, err := strconv.ParseFloat(sample.Labels["le"], 64)
+	if err != nil {
+		return 0
+	}
+
+	return upperBound
+}
+
+// Formats the given labels, sorted by name.
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := []string{}
+	for _, name := range labels.names() {
+		pairs = append(pairs, name+`="`+escapeLabelValue(labels[name])+`"`)
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+)
+
+type testCollector []Family
+
+func (c testCollector) Collect() []Family {
+	return c
+}
+
+func TestWriteTextFormat(t *testing.T) {
+	c := testCollector{
+		{"test_total", "A test\ncounter.", Counter, []Sample{
+			{Labels: Labels{"type": "b"}, Value: 2},
+			{Labels: Labels{"type": `a "quoted"\value`}, Value: 1},
+		}},
+		{"test_gauge", "", Gauge, []Sample{{Value: 0.5}}},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteText(buf, c); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP test_total A test\ncounter.
+# TYPE test_total counter
+test_total{type="a \"quoted\"\\value"} 1
+test_total{type="b"} 2
+# TYPE test_gauge gauge
+test_gauge 0.5
+`
+	if buf.String() != expected {
+		t.Error("invalid text format: ", buf.String())
+	}
+}
+
+func TestWriteTextGroupsHistogramSeries(t *testing.T) {
+	c := testCollector{
+		{"test_seconds", "", Histogram, []Sample{
+			{"_count", Labels{"key": "b"}, 1},
+			{"_bucket", Labels{"key": "a", "le": "+Inf"}, 2},
+			{"_sum", Labels{"key": "a"}, 3},
+			{"_bucket", Labels{"key": "b", "le": "10"}, 1},
+			{"_bucket", Labels{"key": "a", "le": "10"}, 2},
+			{"_count", Labels{"key": "a"}, 2},
+			{"_bucket", Labels{"key": "a", "le": "2"}, 1},
+			{"_sum", Labels{"key": "b"}, 4},
+			{"_bucket", Labels{"key": "b", "le": "+Inf"}, 1},
+		}},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteText(buf, c); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# TYPE test_seconds histogram
+test_seconds_bucket{key="a",le="2"} 1
+test_seconds_bucket{key="a",le="10"} 2
+test_seconds_bucket{key="a",le="+Inf"} 2
+test_seconds_sum{key="a"} 3
+test_seconds_count{key="a"} 2
+test_seconds_bucket{key="b",le="10"} 1
+test_seconds_bucket{key="b",le="+Inf"} 1
+test_seconds_sum{key="b"} 4
+test_seconds_count{key="b"} 1
+`
+	if buf.String() != expected {
+		t.Error("invalid histogram format: ", buf.String())
+	}
+}
+
+func TestHandlerWritesText(t *testing.T) {
+	c := testCollector{{"test_gauge", "A gauge.", Gauge, []Sample{{Value: 1}}}}
+
+	recorder := httptest.NewRecorder()
+	Handler(c).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != textContentType {
+		t.Error("invalid content type: ", contentType)
+	}
+	if body := recorder.Body.String(); body != "# HELP test_gauge A gauge.\n# TYPE test_gauge gauge\ntest_gauge 1\n" {
+		t.Error("invalid body: ", body)
+	}
+}
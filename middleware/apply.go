@@ -34,11 +34,13 @@ func wrapPerformDispatch(dispatch store.PerformDispatch, mws ...Func) store.Perf
 
 	return func(initialCtx context.Context, st store.State, initialAction interface{}) (store.State, error) {
 		updatedSt := store.State{}
-		err := mw(initialCtx, initialAction, createBaseNext(dispatch, st, &updatedSt))
-		if err != nil {
+		ctx := contextWithChangeReport(initialCtx)
+		err := mw(ctx, initialAction, createBaseNext(dispatch, st, &updatedSt))
+		if err != nil && len(updatedSt) == 0 {
 			return nil, err
 		}
 
-		return updatedSt, nil
+		// A partially updated State is returned with the error, so the Store can decide if it should be committed
+		return updatedSt, err
 	}
 }
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"github.com/nheyn/go-redux/store"
 	"testing"
 )
@@ -86,3 +87,66 @@ func TestWrapWillAddMiddlewareToPerformDispatch(t *testing.T) {
 		t.Error("The initial dispatch function recived", dispatchAction, "but it should have been", outMwAction)
 	}
 }
+
+func TestApplyCanBeUsedWithATypedStore(t *testing.T) {
+	mwActions := []interface{}{}
+	mwGen := func(_ *store.Store) Func {
+		return func(ctx context.Context, action interface{}, next Next) error {
+			mwActions = append(mwActions, action)
+
+			return next(ctx, action)
+		}
+	}
+
+	reducer := func(_ context.Context, count int, amount int) (int, error) {
+		return count + amount, nil
+	}
+	testStore := store.NewTyped(0, reducer, Apply(mwGen))
+	defer testStore.Close()
+
+	if err := testStore.Dispatch(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(mwActions) != 1 || mwActions[0] != 5 {
+		t.Error("The middleware should have been given the typed action, but was given", mwActions)
+	}
+
+	count, err := testStore.Select()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 {
+		t.Error("The typed state should be 5, but is", count)
+	}
+}
+
+type testFailingUpdater struct{}
+
+func (testFailingUpdater) Update(_ context.Context, _ interface{}) (store.Updater, error) {
+	return nil, errors.New("test failure")
+}
+
+func TestApplyKeepsPartialCommits(t *testing.T) {
+	mwGen := func(_ *store.Store) Func {
+		return func(ctx context.Context, action interface{}, next Next) error {
+			return next(ctx, action)
+		}
+	}
+
+	testStore := store.New(store.State{
+		"renamed": testRenameUpdater("initial"),
+		"failing": testFailingUpdater{},
+	}, store.PartialCommit, Apply(mwGen))
+	defer testStore.Close()
+
+	if err := testStore.Dispatch(context.Background(), "new name"); err == nil {
+		t.Error("The error from the failing Updater should have been returned")
+	}
+
+	currState := store.State{}
+	testStore.Select(&currState)
+	if currState["renamed"] != testRenameUpdater("new name") {
+		t.Error("The Updater that did not fail should have been committed, but is", currState["renamed"])
+	}
+}
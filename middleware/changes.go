@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"github.com/nheyn/go-redux/store"
+	"sync"
+)
+
+// The key for the changeReport in the context passed to a middleware Func.
+type changesKey struct{}
+
+// Keeps track of the keys that were changed by the calls to a Next function.
+type changeReport struct {
+	mu    sync.Mutex
+	keys  store.KeySet
+	diffs map[interface{}]updaterDiff
+}
+
+// The previous and current version of an Updater that was changed.
+type updaterDiff struct {
+	prev store.Updater
+	curr store.Updater
+}
+
+// ChangedKeys can be called by a middleware Func, after next(...) has returned, to get the keys of the
+// Updaters that were changed by the action. For a partial commit (see store.IsPartialCommit(...)) it has
+// the keys that were applied, even though next(...) returned an error. The given context must be the one
+// passed to the middleware Func (or created from it).
+// Ex)
+//	func(ctx context.Context, action interface{}, next middleware.Next) error {
+//		if err := next(ctx, action); err != nil {
+//			return err
+//		}
+//
+//		log.Println(action, "changed", middleware.ChangedKeys(ctx))
+//		return nil
+//	}
+func ChangedKeys(ctx context.Context) store.KeySet {
+	report, hasReport := ctx.Value(changesKey{}).(*changeReport)
+	if !hasReport {
+		return store.KeySet{}
+	}
+
+	report.mu.Lock()
+	defer report.mu.Unlock()
+
+	keys := make(store.KeySet, len(report.keys))
+	for key := range report.keys {
+		keys[key] = struct{}{}
+	}
+
+	return keys
+}
+
+// Adds a changeReport to the given context, so the changed keys can be accessed by ChangedKeys(...).
+func contextWithChangeReport(ctx context.Context) context.Context {
+	return context.WithValue(ctx, changesKey{}, &changeReport{
+		keys:  store.KeySet{},
+		diffs: map[interface{}]updaterDiff{},
+	})
+}
+
+// Adds the keys of the Updaters that are different in the given States to the changeReport in the given
+// context.
+func reportChanges(ctx context.Context, prevSt, currSt store.State) {
+	if ctx == nil {
+		return
+	}
+
+	report, hasReport := ctx.Value(changesKey{}).(*changeReport)
+	if !hasReport {
+		return
+	}
+
+	report.mu.Lock()
+	defer report.mu.Unlock()
+
+	for key, data := range currSt {
+		if store.Changed(prevSt[key], data) {
+			report.keys[key] = struct{}{}
+
+			diff, hasDiff := report.diffs[key]
+			if !hasDiff {
+				diff.prev = prevSt[key]
+			}
+			diff.curr = data
+			report.diffs[key] = diff
+		}
+	}
+}
+
+// Gets the previous and current versions of the Updaters that were changed, from the changeReport in the
+// given context.
+func changedUpdaters(ctx context.Context) map[interface{}]updaterDiff {
+	report, hasReport := ctx.Value(changesKey{}).(*changeReport)
+	if !hasReport {
+		return map[interface{}]updaterDiff{}
+	}
+
+	report.mu.Lock()
+	defer report.mu.Unlock()
+
+	diffs := make(map[interface{}]updaterDiff, len(report.diffs))
+	for key, diff := range report.diffs {
+		diffs[key] = diff
+	}
+
+	return diffs
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"github.com/nheyn/go-redux/store"
+	"testing"
+)
+
+type testRenameUpdater string
+
+func (t testRenameUpdater) Update(_ context.Context, action interface{}) (store.Updater, error) {
+	if name, isName := action.(string); isName {
+		return testRenameUpdater(name), nil
+	}
+
+	return t, nil
+}
+
+func TestChangedKeysReportsTheChangedUpdaters(t *testing.T) {
+	changed := make(chan store.KeySet, 2)
+	mwGen := func(_ *store.Store) Func {
+		return func(ctx context.Context, action interface{}, next Next) error {
+			if err := next(ctx, action); err != nil {
+				return err
+			}
+
+			changed <- ChangedKeys(ctx)
+			return nil
+		}
+	}
+
+	testStore := store.New(store.State{
+		"renamed":   testRenameUpdater("initial"),
+		"unchanged": testUpdater("unchanged"),
+	}, Apply(mwGen))
+	defer testStore.Close()
+
+	if err := testStore.Dispatch(context.Background(), "new name"); err != nil {
+		t.Fatal(err)
+	}
+	if keys := <-changed; len(keys) != 1 || !keys.Has("renamed") {
+		t.Error("Only the renamed key should have changed, but the changed keys are", keys)
+	}
+
+	if err := testStore.Dispatch(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	if keys := <-changed; len(keys) != 0 {
+		t.Error("No keys should have changed, but the changed keys are", keys)
+	}
+}
+
+func TestChangedKeysReportsPartialCommits(t *testing.T) {
+	changed := make(chan store.KeySet, 1)
+	mwGen := func(_ *store.Store) Func {
+		return func(ctx context.Context, action interface{}, next Next) error {
+			err := next(ctx, action)
+
+			changed <- ChangedKeys(ctx)
+			return err
+		}
+	}
+
+	testStore := store.New(store.State{
+		"renamed": testRenameUpdater("initial"),
+		"failing": testFailingUpdater{},
+	}, Apply(mwGen), store.PartialCommit)
+	defer testStore.Close()
+
+	if err := testStore.Dispatch(context.Background(), "new name"); !store.IsPartialCommit(err) {
+		t.Fatal("The dispatch should have been partially committed, but got", err)
+	}
+	if keys := <-changed; len(keys) != 1 || !keys.Has("renamed") {
+		t.Error("The applied key should have changed, but the changed keys are", keys)
+	}
+
+	st := store.State{}
+	testStore.Select(&st)
+	if st["renamed"] != testRenameUpdater("new name") {
+		t.Error("The applied key should have been committed, but is", st["renamed"])
+	}
+}
+
+func TestChangedKeysIsEmptyOutsideOfMiddleware(t *testing.T) {
+	if keys := ChangedKeys(context.Background()); len(keys) != 0 {
+		t.Error("No keys should be reported without a dispatch, but got", keys)
+	}
+}
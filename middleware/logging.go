@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/nheyn/go-redux/store"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// The key for the Logger in the context passed to the Updaters.
+type loggerKey struct{}
+
+// The options for the middleware created by Logging(...), it is set using config functions (i.e.
+// LogLevel(...)).
+type LoggingConfig struct {
+	level       slog.Level
+	errorLevel  slog.Level
+	sampleEvery uint64
+	logDiffs    bool
+	redactAct   func(action interface{}) interface{}
+	redactData  func(key interface{}, data store.Updater) interface{}
+}
+
+// Logging creates a middleware generator, that should be passed to Apply(...), which logs each action
+// with its type, how long it took and if it returned an error. The logger is also added to the context
+// passed to the Updaters, see LoggerFrom(...).
+// Ex)
+//	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+//	s := store.New(initialState, middleware.Apply(middleware.Logging(
+//		logger,
+//		middleware.LogStateDiffs,
+//		middleware.RedactActions(hidePasswords),
+//	)))
+func Logging(logger *slog.Logger, configs ...func(*LoggingConfig)) func(*store.Store) Func {
+	config := &LoggingConfig{
+		level:       slog.LevelInfo,
+		errorLevel:  slog.LevelError,
+		sampleEvery: 1,
+	}
+	for _, c := range configs {
+		c(config)
+	}
+
+	return func(_ *store.Store) Func {
+		var count uint64
+
+		return func(ctx context.Context, action interface{}, next Next) error {
+			start := time.Now()
+			err := next(context.WithValue(ctx, loggerKey{}, logger), action)
+			duration := time.Since(start)
+
+			// Errors are always logged, other actions are sampled
+			sampled := atomic.AddUint64(&count, 1)%config.sampleEvery == 0
+			if err == nil && !sampled {
+				return nil
+			}
+
+			level := config.level
+			attrs := []slog.Attr{
+				slog.String("action_type", fmt.Sprintf("%T", action)),
+				slog.Duration("duration", duration),
+			}
+			if config.redactAct != nil {
+				attrs = append(attrs, slog.Any("action", config.redactAct(action)))
+			}
+			if err != nil {
+				level = config.errorLevel
+				attrs = append(attrs, slog.String("outcome", "error"), slog.Any("error", err))
+			} else {
+				attrs = append(attrs, slog.String("outcome", "ok"))
+			}
+			if config.logDiffs {
+				attrs = append(attrs, config.diffAttr(ctx))
+			}
+
+			logger.LogAttrs(ctx, level, "dispatch", attrs...)
+			return err
+		}
+	}
+}
+
+// A config function for Logging(...) that sets the level actions are logged at. The default is Info.
+func LogLevel(level slog.Level) func(*LoggingConfig) {
+	return func(config *LoggingConfig) {
+		config.level = level
+	}
+}
+
+// A config function for Logging(...) that sets the level actions that return an error are logged at. The
+// default is Error.
+func LogErrorLevel(level slog.Level) func(*LoggingConfig) {
+	return func(config *LoggingConfig) {
+		config.errorLevel = level
+	}
+}
+
+// A config function for Logging(...) that only logs one out of every given number of actions. Actions
+// that return an error are always logged.
+func SampleEvery(actions uint64) func(*LoggingConfig) {
+	return func(config *LoggingConfig) {
+		if actions > 0 {
+			config.sampleEvery = actions
+		}
+	}
+}
+
+// A config function for Logging(...) that logs the previous and current version of each Updater that was
+// changed by the action.
+func LogStateDiffs(config *LoggingConfig) {
+	config.logDiffs = true
+}
+
+// A config function for Logging(...) that logs the value of each action, after passing it to the given
+// function so any sensitive fields can be removed.
+func RedactActions(redact func(action interface{}) interface{}) func(*LoggingConfig) {
+	return func(config *LoggingConfig) {
+		config.redactAct = redact
+	}
+}
+
+// A config function for Logging(...) that passes each Updater to the given function before it is logged
+// by LogStateDiffs, so any sensitive fields can be removed.
+func RedactState(redact func(key interface{}, data store.Updater) interface{}) func(*LoggingConfig) {
+	return func(config *LoggingConfig) {
+		config.redactData = redact
+	}
+}
+
+// LoggerFrom can be called in the .Update(...) method of an Updater to get the logger from Logging(...).
+// The State key of the Updater, see store.KeyFrom(...), is added to the logger. If the Store does not
+// have the Logging middleware, slog.Default() is used.
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	logger, hasLogger := ctx.Value(loggerKey{}).(*slog.Logger)
+	if !hasLogger {
+		logger = slog.Default()
+	}
+
+	if key, hasKey := store.KeyFrom(ctx); hasKey {
+		logger = logger.With(slog.Any("key", key))
+	}
+
+	return logger
+}
+
+// Creates the attribute with the Updaters that were changed by the action.
+func (config *LoggingConfig) diffAttr(ctx context.Context) slog.Attr {
+	attrs := []any{}
+	for key, diff := range changedUpdaters(ctx) {
+		var prev, curr interface{} = diff.prev, diff.curr
+		if config.redactData != nil {
+			prev, curr = config.redactData(key, diff.prev), config.redactData(key, diff.curr)
+		}
+
+		attrs = append(attrs, slog.Group(fmt.Sprint(key), slog.Any("prev", prev), slog.Any("curr", curr)))
+	}
+
+	return slog.Group("changes", attrs...)
+}
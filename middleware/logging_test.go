@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/nheyn/go-redux/store"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type testSecret struct {
+	Name     string
+	Password string
+}
+
+type testLoggingUpdater string
+
+func (u testLoggingUpdater) Update(ctx context.Context, action interface{}) (store.Updater, error) {
+	switch a := action.(type) {
+	case testSecret:
+		LoggerFrom(ctx).Info("updating")
+		return testLoggingUpdater(a.Name), nil
+	case error:
+		return nil, a
+	}
+
+	return u, nil
+}
+
+func newTestLogger() (*slog.Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})), buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	lines := []map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, entry)
+	}
+
+	return lines
+}
+
+func TestLoggingLogsEachAction(t *testing.T) {
+	logger, buf := newTestLogger()
+	testStore := store.New(store.State{"user": testLoggingUpdater("")}, Apply(Logging(logger, LogLevel(slog.LevelDebug))))
+	defer testStore.Close()
+
+	testStore.Dispatch(context.Background(), testSecret{"name", "password"})
+	testStore.Dispatch(context.Background(), errors.New("test error"))
+
+	lines := logLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatal("There should be a log line from the Updater and for each action, but there are", len(lines))
+	}
+
+	if lines[0]["msg"] != "updating" || lines[0]["key"] != "user" {
+		t.Error("The Updater should log with its key, but logged", lines[0])
+	}
+	if lines[1]["level"] != "DEBUG" || lines[1]["outcome"] != "ok" || lines[1]["action_type"] != "middleware.testSecret" {
+		t.Error("The successful action should have been logged, but logged", lines[1])
+	}
+	if _, hasDuration := lines[1]["duration"]; !hasDuration {
+		t.Error("The duration of the action should have been logged")
+	}
+	if _, hasAction := lines[1]["action"]; hasAction {
+		t.Error("The action should not be logged without a redaction function")
+	}
+	if lines[2]["level"] != "ERROR" || lines[2]["outcome"] != "error" || lines[2]["error"] == nil {
+		t.Error("The failed action should have been logged as an error, but logged", lines[2])
+	}
+}
+
+func TestLoggingCanSampleActions(t *testing.T) {
+	logger, buf := newTestLogger()
+	testStore := store.New(store.State{"user": testLoggingUpdater("")}, Apply(Logging(logger, SampleEvery(3))))
+	defer testStore.Close()
+
+	for i := 0; i < 6; i++ {
+		testStore.Dispatch(context.Background(), i)
+	}
+	testStore.Dispatch(context.Background(), errors.New("test error"))
+
+	if lines := logLines(t, buf); len(lines) != 3 {
+		t.Error("Every third action, and the error, should have been logged, but there are", len(lines), "lines")
+	}
+}
+
+func TestLoggingCanLogRedactedActionsAndDiffs(t *testing.T) {
+	logger, buf := newTestLogger()
+	redactAction := func(action interface{}) interface{} {
+		if secret, isSecret := action.(testSecret); isSecret {
+			secret.Password = "[REDACTED]"
+			return secret
+		}
+
+		return action
+	}
+	redactState := func(key interface{}, data store.Updater) interface{} {
+		return strings.ToUpper(string(data.(testLoggingUpdater)))
+	}
+	testStore := store.New(store.State{"user": testLoggingUpdater("old")}, Apply(Logging(
+		logger,
+		LogStateDiffs,
+		RedactActions(redactAction),
+		RedactState(redactState),
+	)))
+	defer testStore.Close()
+
+	testStore.Dispatch(context.Background(), testSecret{"new", "password"})
+
+	output := buf.String()
+	if strings.Contains(output, `"password"`) || !strings.Contains(output, "[REDACTED]") {
+		t.Error("The password should have been redacted, but the log is", output)
+	}
+
+	lines := logLines(t, buf)
+	changes, _ := lines[len(lines)-1]["changes"].(map[string]interface{})
+	user, _ := changes["user"].(map[string]interface{})
+	if user["prev"] != "OLD" || user["curr"] != "NEW" {
+		t.Error("The redacted diff of the Updater should have been logged, but logged", changes)
+	}
+}
+
+func TestLoggerFromUsesTheDefaultLogger(t *testing.T) {
+	if LoggerFrom(context.Background()) == nil {
+		t.Error("The default logger should be returned when there is no Logging middleware")
+	}
+}
@@ -34,15 +34,17 @@ func createNextGenerator(mws ...Func) func(Next) Next {
 
 // Returns a Next function that will perform the default dispatch(...) behavoir when called by
 // a middleware Func. The new will be saved in updatedState after the returned next func is
-// called, unless it returned an error.
+// called, unless it returned an error that is not for a partial commit (see store.PartialCommit(...)).
+// The changed keys are saved for ChangedKeys(...).
 func createBaseNext(dispatch store.PerformDispatch, st store.State, updatedSt *store.State) Next {
 	return func(ctx context.Context, action interface{}) error {
 		newSt, err := dispatch(ctx, st, action)
-		if err != nil {
+		if err != nil && (newSt == nil || !store.IsPartialCommit(err)) {
 			return err
 		}
 
 		updatedSt.SelectFrom(&newSt)
-		return nil
+		reportChanges(ctx, st, newSt)
+		return err
 	}
 }
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/nheyn/go-redux/store"
+)
+
+// ErrNoThunkMiddleware is returned by DispatchThunk(...) when the Store does not use the ThunkMiddleware, so
+// the Thunk was not called.
+var ErrNoThunkMiddleware = errors.New("middleware: the store does not use the thunk middleware")
+
+// A Thunk is an action that contains logic, instead of data, which can read the State and dispatch other
+// actions. When it is dispatched to a Store using the ThunkMiddleware, it is called instead of being
+// passed to the Updaters.
+type Thunk func(ctx context.Context, dispatch Dispatch, getState GetState) error
+
+// A Dispatch function will dispatch the given action to a Store, see store.Store.Dispatch(...).
+type Dispatch func(context.Context, interface{}) error
+
+// A GetState function will allow the given selector to pull data from the current State of a Store, see
+// store.Store.Select(...).
+type GetState func(store.Selector) error
+
+// A ThunkResult is the result of a Thunk that was dispatched using DispatchThunk(...).
+type ThunkResult struct {
+	started bool
+	done    chan struct{}
+	err     error
+}
+
+// The action that is dispatched by DispatchThunk(...).
+type thunkAction struct {
+	thunk  Thunk
+	result *ThunkResult
+}
+
+// ThunkMiddleware is a middleware generator, that can be passed to Apply(...), which will call any
+// dispatched Thunks. Because only one action can be performed at a time, each Thunk is called on its
+// own goroutine after its dispatch has completed, so it can dispatch other actions.
+// NOTE: Store.Dispatch(...) DOES NOT wait for the Thunk, it returns as soon as the Thunk has been started.
+// The Thunk is passed a context that is not cancelled when the context of the dispatch is, so the Thunk
+// can outlive it. Any errors returned by the Thunks are ignored, use DispatchThunk(...) to wait for the
+// Thunk and get its error, or see ThunkMiddlewareWithErrors(...).
+func ThunkMiddleware(s *store.Store) Func {
+	return ThunkMiddlewareWithErrors(nil)(s)
+}
+
+// ThunkMiddlewareWithErrors creates a middleware generator, like ThunkMiddleware, that will pass any
+// errors returned by the Thunks to the given function. The errors from Thunks dispatched using
+// DispatchThunk(...) are returned from their ThunkResult instead.
+func ThunkMiddlewareWithErrors(onError func(Thunk, error)) func(*store.Store) Func {
+	return func(s *store.Store) Func {
+		return func(ctx context.Context, action interface{}, next Next) error {
+			var thunk Thunk
+			var result *ThunkResult
+			switch fn := action.(type) {
+			case Thunk:
+				thunk = fn
+			case func(context.Context, Dispatch, GetState) error:
+				thunk = fn
+			case thunkAction:
+				thunk = fn.thunk
+				result = fn.result
+				result.started = true
+			default:
+				return next(ctx, action)
+			}
+
+			// The dispatch is done before the Thunk is called, so its context may already be cancelled
+			thunkCtx := context.WithoutCancel(ctx)
+			go func() {
+				err := thunk(thunkCtx, s.Dispatch, s.Select)
+				if result != nil {
+					result.complete(err)
+				} else if err != nil && onError != nil {
+					onError(thunk, err)
+				}
+			}()
+
+			return nil
+		}
+	}
+}
+
+// DispatchThunk dispatches the given Thunk to the given Store, which must use the ThunkMiddleware, and
+// returns a ThunkResult that can be used to wait for the Thunk to return. If the dispatch fails, its error is
+// returned and the Thunk is not called.
+// Ex)
+//	result, err := middleware.DispatchThunk(ctx, s, fetchTodos)
+//	if err != nil {
+//		return err
+//	}
+//
+//	return result.Wait(ctx)
+func DispatchThunk(ctx context.Context, s *store.Store, thunk Thunk) (*ThunkResult, error) {
+	result := &ThunkResult{done: make(chan struct{})}
+	if err := s.Dispatch(ctx, thunkAction{thunk, result}); err != nil {
+		return nil, err
+	}
+	if !result.started {
+		return nil, ErrNoThunkMiddleware
+	}
+
+	return result, nil
+}
+
+// Done returns a channel that is closed when the Thunk has returned.
+func (r *ThunkResult) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait waits for the Thunk to return, and returns its error. If the given context is done first, its
+// error is returned instead.
+func (r *ThunkResult) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sets the error returned by the Thunk, and marks the ThunkResult as done.
+func (r *ThunkResult) complete(err error) {
+	r.err = err
+	close(r.done)
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/nheyn/go-redux/store"
+	"testing"
+	"time"
+)
+
+func TestThunkMiddlewareCallsThunks(t *testing.T) {
+	testStore := store.New(store.State{"testKey": testUpdater("testUpdater")}, Apply(ThunkMiddleware))
+	defer testStore.Close()
+
+	done := make(chan struct{})
+	var selectedState store.State
+	var dispatchErr error
+	var thunk Thunk = func(ctx context.Context, dispatch Dispatch, getState GetState) error {
+		defer close(done)
+
+		selectedState = store.State{}
+		if err := getState(&selectedState); err != nil {
+			return err
+		}
+
+		dispatchErr = dispatch(ctx, "action from thunk")
+		return nil
+	}
+
+	if err := testStore.Dispatch(context.Background(), thunk); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+
+	if selectedState["testKey"] != testUpdater("testUpdater") {
+		t.Error("The thunk was not able to select the state of the store")
+	}
+	if dispatchErr != nil {
+		t.Error("The thunk was not able to dispatch an action:", dispatchErr)
+	}
+}
+
+func TestThunkMiddlewareForwardsOtherActions(t *testing.T) {
+	var nextAction interface{}
+	mw := ThunkMiddleware(nil)
+
+	err := mw(context.Background(), "test action", func(_ context.Context, action interface{}) error {
+		nextAction = action
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nextAction != "test action" {
+		t.Error("The thunk middleware should have passed the action to next, but passed", nextAction)
+	}
+}
+
+func TestThunkMiddlewareWithErrorsReportsErrors(t *testing.T) {
+	thunkErrs := make(chan error, 1)
+	onError := func(_ Thunk, err error) {
+		thunkErrs <- err
+	}
+
+	testStore := store.New(store.State{}, Apply(ThunkMiddlewareWithErrors(onError)))
+	defer testStore.Close()
+
+	testErr := errors.New("test error")
+	thunk := func(_ context.Context, _ Dispatch, _ GetState) error {
+		return testErr
+	}
+
+	if err := testStore.Dispatch(context.Background(), thunk); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-thunkErrs; err != testErr {
+		t.Error("The error from the thunk should have been reported, but", err, "was")
+	}
+}
+
+func TestThunksAreNotCancelledWithTheirDispatch(t *testing.T) {
+	testStore := store.New(store.State{"testKey": testUpdater("testUpdater")}, Apply(ThunkMiddleware))
+	defer testStore.Close()
+
+	started := make(chan struct{})
+	thunk := func(ctx context.Context, dispatch Dispatch, _ GetState) error {
+		<-started
+
+		return dispatch(ctx, "action from thunk")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	result, err := DispatchThunk(ctx, testStore, thunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	close(started)
+
+	if err := result.Wait(context.Background()); err != nil {
+		t.Error("The thunk should be able to dispatch after the context of its dispatch is cancelled, but got", err)
+	}
+}
+
+func TestDispatchThunkReturnsTheThunkError(t *testing.T) {
+	thunkErrs := make(chan error, 1)
+	onError := func(_ Thunk, err error) {
+		thunkErrs <- err
+	}
+
+	testStore := store.New(store.State{}, Apply(ThunkMiddlewareWithErrors(onError)))
+	defer testStore.Close()
+
+	testErr := errors.New("test error")
+	thunk := func(_ context.Context, _ Dispatch, _ GetState) error {
+		return testErr
+	}
+
+	result, err := DispatchThunk(context.Background(), testStore, thunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := result.Wait(context.Background()); err != testErr {
+		t.Error("The error from the thunk should have been returned, but", err, "was")
+	}
+	if len(thunkErrs) != 0 {
+		t.Error("The error should not also be passed to the error function")
+	}
+}
+
+func TestDispatchThunkRequiresTheThunkMiddleware(t *testing.T) {
+	testStore := store.New(store.State{"testKey": testUpdater("testUpdater")})
+	defer testStore.Close()
+
+	thunk := func(_ context.Context, _ Dispatch, _ GetState) error {
+		return nil
+	}
+
+	if _, err := DispatchThunk(context.Background(), testStore, thunk); err != ErrNoThunkMiddleware {
+		t.Error("Dispatching a thunk without the middleware should return ErrNoThunkMiddleware, but returned", err)
+	}
+}
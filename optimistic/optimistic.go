@@ -0,0 +1,292 @@
+package optimistic
+
+import (
+	"context"
+	"errors"
+	"github.com/nheyn/go-redux/store"
+	"sync"
+)
+
+// ErrUnknownToken is returned when a Token is committed or reverted, but it is not pending.
+var ErrUnknownToken = errors.New("optimistic: the token is not pending")
+
+// ErrNotApplied is returned when the Updates has not been added to a Store using Config(...).
+var ErrNotApplied = errors.New("optimistic: the updates have not been applied to a store")
+
+// A Token identifies an optimistic action, so it can be committed or reverted.
+type Token uint64
+
+// An Action is dispatched by Updates.Dispatch(...), the Updaters are sent the wrapped action.
+type Action struct {
+	Token  Token
+	Action interface{}
+}
+
+// A CommitAction is dispatched by Updates.Commit(...), it keeps the optimistic action with the Token.
+type CommitAction struct {
+	Token Token
+}
+
+// A RevertAction is dispatched by Updates.Revert(...), it removes the optimistic action with the Token
+// and replays the actions that were dispatched after it.
+type RevertAction struct {
+	Token Token
+}
+
+// Updates allows actions to be dispatched optimistically, so the State is updated right away and the
+// action can be reverted later (i.e. when a request to a slow service fails). While any optimistic
+// actions are pending, all of the committed actions are recorded so they can be replayed when one is
+// reverted.
+// Ex)
+//	u := optimistic.New()
+//	s := store.New(initialState, u.Config)
+//
+//	token, err := u.Dispatch(ctx, addTodo)
+//	if err := saveTodo(todo); err != nil {
+//		u.Revert(ctx, token)
+//	} else {
+//		u.Commit(ctx, token)
+//	}
+type Updates struct {
+	mu              sync.Mutex
+	store           *store.Store
+	performDispatch store.PerformDispatch
+	nextToken       Token
+	checkpoint      store.State
+	entries         []entry
+	reverted        *revert
+}
+
+// An action that was committed while there were pending optimistic actions.
+type entry struct {
+	token   Token
+	pending bool
+	action  interface{}
+	after   store.State
+}
+
+// The entries after a RevertAction was performed, they replace the entries once it is committed.
+type revert struct {
+	token   Token
+	entries []entry
+}
+
+// Creates a new Updates.
+func New() *Updates {
+	return &Updates{}
+}
+
+// Config is a config function for store.New(...), it will record the actions dispatched while there are
+// pending optimistic actions and handle the CommitActions and RevertActions. When an action is reverted,
+// the actions after it are replayed using the PerformDispatch the Store had when Config was applied, so
+// it should be applied before any middleware (i.e. thunks or logging) that should not be run again. The
+// pending actions are only changed once the CommitAction or RevertAction has been performed, so they are
+// not changed if it is rejected (see store.BeforeCommit(...)).
+// Ex)
+//	s := store.New(initialState, u.Config, middleware.Apply(middleware.ThunkMiddleware))
+func (u *Updates) Config(s *store.Store) {
+	u.mu.Lock()
+	u.store = s
+	u.performDispatch = s.PerformDispatch
+	u.mu.Unlock()
+
+	s.PerformDispatch = func(ctx context.Context, st store.State, action interface{}) (store.State, error) {
+		switch a := action.(type) {
+		case Action:
+			return u.performDispatch(ctx, st, a.Action)
+		case CommitAction:
+			return store.State{}, u.checkPending(a.Token)
+		case RevertAction:
+			return u.revert(ctx, st, a.Token)
+		default:
+			return u.performDispatch(ctx, st, action)
+		}
+	}
+
+	store.OnDispatched(u.record)(s)
+}
+
+// Dispatch sends the given action to the Store, and returns a Token that must later be passed to
+// Commit(...) or Revert(...). If the action fails, it is not pending so there is nothing to commit or
+// revert.
+func (u *Updates) Dispatch(ctx context.Context, action interface{}) (Token, error) {
+	u.mu.Lock()
+	s := u.store
+	u.nextToken++
+	token := u.nextToken
+	u.mu.Unlock()
+
+	if s == nil {
+		return 0, ErrNotApplied
+	}
+
+	return token, s.Dispatch(ctx, Action{token, action})
+}
+
+// Commit keeps the optimistic action with the given Token.
+func (u *Updates) Commit(ctx context.Context, token Token) error {
+	return u.dispatch(ctx, CommitAction{token})
+}
+
+// Revert removes the optimistic action with the given Token, and rebases the actions that were dispatched
+// after it on the State from before it. Any of those actions that now return an error are dropped.
+func (u *Updates) Revert(ctx context.Context, token Token) error {
+	return u.dispatch(ctx, RevertAction{token})
+}
+
+// Pending returns the Tokens of the optimistic actions that have not been committed or reverted.
+func (u *Updates) Pending() []Token {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	tokens := []Token{}
+	for _, e := range u.entries {
+		if e.pending {
+			tokens = append(tokens, e.token)
+		}
+	}
+
+	return tokens
+}
+
+// Dispatches the given action to the Store.
+func (u *Updates) dispatch(ctx context.Context, action interface{}) error {
+	u.mu.Lock()
+	s := u.store
+	u.mu.Unlock()
+
+	if s == nil {
+		return ErrNotApplied
+	}
+
+	return s.Dispatch(ctx, action)
+}
+
+// Records the given Change. An optimistic action is added as a pending entry, and any other action that
+// changed the State is added if there are pending optimistic actions. A CommitAction or RevertAction
+// updates the pending entries.
+func (u *Updates) record(change store.Change) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	switch a := change.Action.(type) {
+	case Action:
+		if len(u.entries) == 0 {
+			u.checkpoint = change.Previous
+		}
+
+		u.entries = append(u.entries, entry{a.Token, true, a.Action, change.Current})
+	case CommitAction:
+		if i, err := u.find(a.Token); err == nil {
+			u.entries[i].pending = false
+			u.trim()
+		}
+	case RevertAction:
+		if u.reverted != nil && u.reverted.token == a.Token {
+			u.entries = u.reverted.entries
+			u.reverted = nil
+			u.trim()
+		}
+	default:
+		// The actions that did not change the State do not need to be replayed (i.e. thunks)
+		if len(u.entries) > 0 && len(change.ChangedKeys) > 0 {
+			u.entries = append(u.entries, entry{action: change.Action, after: change.Current})
+		}
+	}
+}
+
+// Checks that the entry with the given Token is pending.
+func (u *Updates) checkPending(token Token) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	_, err := u.find(token)
+	return err
+}
+
+// Replays the entries after the one with the given Token, and returns the rebased State to replace the
+// given State with. The rebased entries are used once the RevertAction is committed, see record(...).
+func (u *Updates) revert(ctx context.Context, st store.State, token Token) (store.State, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	i, err := u.find(token)
+	if err != nil {
+		return nil, err
+	}
+
+	rebasedSt := u.checkpoint
+	if i > 0 {
+		rebasedSt = u.entries[i-1].after
+	}
+
+	rebased := append([]entry{}, u.entries[:i]...)
+	for _, e := range u.entries[i+1:] {
+		after, err := u.replay(ctx, rebasedSt, e.action)
+		if err != nil {
+			continue
+		}
+
+		e.after = after
+		rebased = append(rebased, e)
+		rebasedSt = after
+	}
+
+	u.reverted = &revert{token, rebased}
+	return store.ReplaceState(st, rebasedSt), nil
+}
+
+// Performs the given action, or each action in a store.Batch, on a copy of the given State. The actions
+// that add or remove Updaters (see store.AddUpdater(...)) change the keys of the State.
+func (u *Updates) replay(ctx context.Context, st store.State, action interface{}) (store.State, error) {
+	actions, isBatch := action.(store.Batch)
+	if !isBatch {
+		actions = store.Batch{action}
+	}
+
+	updated := store.CopyState(st)
+	for _, action := range actions {
+		if a, isOptimistic := action.(Action); isOptimistic {
+			action = a.Action
+		}
+
+		newState, err := u.performDispatch(ctx, store.CopyState(updated), action)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, data := range newState {
+			if data == nil {
+				delete(updated, key)
+			} else {
+				updated[key] = data
+			}
+		}
+	}
+
+	return updated, nil
+}
+
+// Removes the entries, at the start, that are no longer needed because there are no pending optimistic
+// actions before them. Must be called with the lock held.
+func (u *Updates) trim() {
+	for len(u.entries) > 0 && !u.entries[0].pending {
+		u.checkpoint = u.entries[0].after
+		u.entries = u.entries[1:]
+	}
+
+	if len(u.entries) == 0 {
+		u.checkpoint = nil
+	}
+}
+
+// Finds the index of the pending entry with the given Token. Must be called with the lock held.
+func (u *Updates) find(token Token) (int, error) {
+	for i, e := range u.entries {
+		if e.pending && e.token == token {
+			return i, nil
+		}
+	}
+
+	return 0, ErrUnknownToken
+}
@@ -0,0 +1,299 @@
+package optimistic
+
+import (
+	"context"
+	"errors"
+	"github.com/nheyn/go-redux/middleware"
+	"github.com/nheyn/go-redux/store"
+	"sync/atomic"
+	"testing"
+)
+
+type testList []string
+
+func (l testList) Update(_ context.Context, action interface{}) (store.Updater, error) {
+	switch a := action.(type) {
+	case string:
+		if a == "invalid" {
+			return nil, errors.New("invalid item")
+		}
+
+		return append(append(testList{}, l...), a), nil
+	case testRemove:
+		for i, item := range l {
+			if item == string(a) {
+				return append(append(testList{}, l[:i]...), l[i+1:]...), nil
+			}
+		}
+
+		return nil, errors.New("missing item")
+	}
+
+	return l, nil
+}
+
+func (l testList) Equal(other store.Updater) bool {
+	otherList, isList := other.(testList)
+
+	return isList && equalLists(l, otherList)
+}
+
+type testRemove string
+
+func newTestStore() (*Updates, *store.Store) {
+	u := New()
+	s := store.New(store.State{"list": testList{}}, u.Config)
+
+	return u, s
+}
+
+func listOf(t *testing.T, s *store.Store) testList {
+	st := store.State{}
+	if err := s.Select(&st); err != nil {
+		t.Fatal(err)
+	}
+
+	return st["list"].(testList)
+}
+
+func equalLists(a, b testList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestUpdatesAreAppliedRightAway(t *testing.T) {
+	u, s := newTestStore()
+	defer s.Close()
+
+	token, err := u.Dispatch(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if list := listOf(t, s); !equalLists(list, testList{"a"}) {
+		t.Error("The optimistic action should have updated the State, but the list is", list)
+	}
+	if pending := u.Pending(); len(pending) != 1 || pending[0] != token {
+		t.Error("The token should be pending, but the pending tokens are", pending)
+	}
+
+	if err := u.Commit(context.Background(), token); err != nil {
+		t.Fatal(err)
+	}
+	if list := listOf(t, s); !equalLists(list, testList{"a"}) {
+		t.Error("Committing should keep the State, but the list is", list)
+	}
+	if pending := u.Pending(); len(pending) != 0 {
+		t.Error("The token should no longer be pending, but the pending tokens are", pending)
+	}
+}
+
+func TestRevertRebasesTheLaterActions(t *testing.T) {
+	u, s := newTestStore()
+	defer s.Close()
+	ctx := context.Background()
+
+	s.Dispatch(ctx, "a")
+	token, _ := u.Dispatch(ctx, "b")
+	s.Dispatch(ctx, "c")
+	s.DispatchBatch(ctx, "d", "e")
+
+	if err := u.Revert(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+
+	if list := listOf(t, s); !equalLists(list, testList{"a", "c", "d", "e"}) {
+		t.Error("The optimistic action should have been removed, but the list is", list)
+	}
+	if err := u.Commit(ctx, token); err != ErrUnknownToken {
+		t.Error("A reverted token should not be pending, but got", err)
+	}
+}
+
+func TestRevertKeepsOtherOptimisticActions(t *testing.T) {
+	u, s := newTestStore()
+	defer s.Close()
+	ctx := context.Background()
+
+	first, _ := u.Dispatch(ctx, "a")
+	second, _ := u.Dispatch(ctx, "b")
+	third, _ := u.Dispatch(ctx, "c")
+
+	u.Commit(ctx, first)
+	u.Revert(ctx, second)
+
+	if list := listOf(t, s); !equalLists(list, testList{"a", "c"}) {
+		t.Error("Only the reverted action should have been removed, but the list is", list)
+	}
+	if pending := u.Pending(); len(pending) != 1 || pending[0] != third {
+		t.Error("The third token should still be pending, but the pending tokens are", pending)
+	}
+
+	u.Revert(ctx, third)
+	if list := listOf(t, s); !equalLists(list, testList{"a"}) {
+		t.Error("The third action should have been removed, but the list is", list)
+	}
+}
+
+func TestRevertDropsActionsThatNoLongerApply(t *testing.T) {
+	u, s := newTestStore()
+	defer s.Close()
+	ctx := context.Background()
+
+	token, _ := u.Dispatch(ctx, "a")
+	s.Dispatch(ctx, testRemove("a"))
+	s.Dispatch(ctx, "b")
+
+	if err := u.Revert(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	if list := listOf(t, s); !equalLists(list, testList{"b"}) {
+		t.Error("The action that depended on the reverted action should have been dropped, but the list is", list)
+	}
+}
+
+func TestRevertNotifiesSubscribers(t *testing.T) {
+	u, s := newTestStore()
+	defer s.Close()
+	ctx := context.Background()
+
+	token, _ := u.Dispatch(ctx, "a")
+
+	changes := make(chan store.Change, 1)
+	if _, err := s.SubscribeChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+	u.Revert(ctx, token)
+
+	change := <-changes
+	if revert, isRevert := change.Action.(RevertAction); !isRevert || revert.Token != token {
+		t.Error("The change should be for the RevertAction, but is for", change.Action)
+	}
+}
+
+func TestUpdatesMustBeApplied(t *testing.T) {
+	u := New()
+
+	if _, err := u.Dispatch(context.Background(), "a"); err != ErrNotApplied {
+		t.Error("ErrNotApplied should have been returned, but got", err)
+	}
+	if err := u.Revert(context.Background(), 1); err != ErrNotApplied {
+		t.Error("ErrNotApplied should have been returned, but got", err)
+	}
+}
+
+func TestFailedOptimisticActionsAreNotPending(t *testing.T) {
+	u, s := newTestStore()
+	defer s.Close()
+
+	if _, err := u.Dispatch(context.Background(), "invalid"); err == nil {
+		t.Error("The error from the Updater should have been returned")
+	}
+	if pending := u.Pending(); len(pending) != 0 {
+		t.Error("A failed action should not be pending, but the pending tokens are", pending)
+	}
+}
+
+func TestRevertKeepsRemovedUpdatersRemoved(t *testing.T) {
+	u := New()
+	s := store.New(store.State{"list": testList{}, "b": testList{}}, u.Config)
+	defer s.Close()
+	ctx := context.Background()
+
+	token, _ := u.Dispatch(ctx, "a")
+	if err := s.RemoveUpdater(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Revert(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+
+	st := store.State{}
+	if err := s.Select(&st); err != nil {
+		t.Fatal(err)
+	}
+	if _, hasB := st["b"]; hasB {
+		t.Error("The removed Updater should not have been added back, but the State is", st)
+	}
+	if list := st["list"].(testList); len(list) != 0 {
+		t.Error("The optimistic action should have been removed, but the list is", list)
+	}
+}
+
+func TestRejectedRevertKeepsTheActionPending(t *testing.T) {
+	rejected := errors.New("rejected")
+	u := New()
+	s := store.New(store.State{"list": testList{}}, u.Config, store.BeforeCommit(func(change store.Change) error {
+		if _, isRevert := change.Action.(RevertAction); isRevert {
+			return rejected
+		}
+
+		return nil
+	}))
+	defer s.Close()
+	ctx := context.Background()
+
+	token, _ := u.Dispatch(ctx, "a")
+	if err := u.Revert(ctx, token); err == nil {
+		t.Fatal("The rejected revert should have returned an error")
+	}
+
+	if pending := u.Pending(); len(pending) != 1 || pending[0] != token {
+		t.Error("The token should still be pending, but the pending tokens are", pending)
+	}
+	if list := listOf(t, s); !equalLists(list, testList{"a"}) {
+		t.Error("The rejected revert should not have changed the State, but the list is", list)
+	}
+	if err := u.Commit(ctx, token); err != nil {
+		t.Error("The token should still be committable, but got", err)
+	}
+}
+
+func TestRevertDoesNotRunThunksAgain(t *testing.T) {
+	orders := map[string]func(*Updates) []func(*store.Store){
+		"config first": func(u *Updates) []func(*store.Store) {
+			return []func(*store.Store){u.Config, middleware.Apply(middleware.ThunkMiddleware)}
+		},
+		"middleware first": func(u *Updates) []func(*store.Store) {
+			return []func(*store.Store){middleware.Apply(middleware.ThunkMiddleware), u.Config}
+		},
+	}
+
+	for name, configsFor := range orders {
+		u := New()
+		s := store.New(store.State{"list": testList{}}, configsFor(u)...)
+		ctx := context.Background()
+
+		var runs int32
+		token, _ := u.Dispatch(ctx, "a")
+		result, err := middleware.DispatchThunk(ctx, s, func(_ context.Context, _ middleware.Dispatch, _ middleware.GetState) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		result.Wait(ctx)
+		s.Dispatch(ctx, "b")
+
+		if err := u.Revert(ctx, token); err != nil {
+			t.Fatal(err)
+		}
+		if count := atomic.LoadInt32(&runs); count != 1 {
+			t.Error("With the", name, "the thunk should only have run once, but ran", count, "times")
+		}
+		if list := listOf(t, s); !equalLists(list, testList{"b"}) {
+			t.Error("With the", name, "the later action should have been replayed, but the list is", list)
+		}
+		s.Close()
+	}
+}
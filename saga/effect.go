@@ -0,0 +1,133 @@
+package saga
+
+import (
+	"context"
+	"github.com/nheyn/go-redux/store"
+	"reflect"
+	"time"
+)
+
+// An Effect is a description of a side-effect that a Saga wants to perform. It is passed to Task.Do(...),
+// which will perform it and return its result.
+type Effect interface {
+	isEffect()
+}
+
+// A Pattern checks if a dispatched action is the one a TakeEffect is waiting for.
+type Pattern func(action interface{}) bool
+
+// Any is a Pattern that matches every action.
+func Any(_ interface{}) bool {
+	return true
+}
+
+// TypeOf creates a Pattern that matches actions with the same type as the given action.
+func TypeOf(example interface{}) Pattern {
+	exampleType := reflect.TypeOf(example)
+
+	return func(action interface{}) bool {
+		return reflect.TypeOf(action) == exampleType
+	}
+}
+
+// A TakeEffect waits for an action, that matches the Pattern, to be dispatched. The result is the action.
+type TakeEffect struct {
+	Pattern Pattern
+}
+
+// A PutEffect dispatches the Action to the Store.
+type PutEffect struct {
+	Action interface{}
+}
+
+// A CallEffect calls Fn, the result is the value returned by it. The context passed to Fn is cancelled
+// when the Task is.
+type CallEffect struct {
+	Fn func(context.Context) (interface{}, error)
+}
+
+// A ForkEffect starts the Saga on a new Task, without waiting for it. The result is the new *Task, which
+// is cancelled when its parent is.
+type ForkEffect struct {
+	Saga Saga
+}
+
+// A CancelEffect cancels the Task.
+type CancelEffect struct {
+	Task *Task
+}
+
+// A RaceEffect performs all of the Effects at the same time, and cancels the rest once one has completed.
+// The result is a RaceResult.
+type RaceEffect struct {
+	Effects map[string]Effect
+}
+
+// A RaceResult is the result of a RaceEffect.
+type RaceResult struct {
+	// The key, in RaceEffect.Effects, of the Effect that completed first.
+	Key string
+	// The result of the Effect that completed first.
+	Value interface{}
+}
+
+// A DelayEffect waits for the Duration.
+type DelayEffect struct {
+	Duration time.Duration
+}
+
+// A SelectEffect allows the Selector to pull data from the current State of the Store.
+type SelectEffect struct {
+	Selector store.Selector
+}
+
+func (TakeEffect) isEffect()   {}
+func (PutEffect) isEffect()    {}
+func (CallEffect) isEffect()   {}
+func (ForkEffect) isEffect()   {}
+func (CancelEffect) isEffect() {}
+func (RaceEffect) isEffect()   {}
+func (DelayEffect) isEffect()  {}
+func (SelectEffect) isEffect() {}
+
+// Take creates an Effect that waits for an action that matches the given Pattern.
+func Take(pattern Pattern) Effect {
+	return TakeEffect{pattern}
+}
+
+// Put creates an Effect that dispatches the given action.
+func Put(action interface{}) Effect {
+	return PutEffect{action}
+}
+
+// Call creates an Effect that calls the given function. The function should stop when the given context
+// is done.
+func Call(fn func(context.Context) (interface{}, error)) Effect {
+	return CallEffect{fn}
+}
+
+// Fork creates an Effect that starts the given Saga on a new Task.
+func Fork(saga Saga) Effect {
+	return ForkEffect{saga}
+}
+
+// Cancel creates an Effect that cancels the given Task.
+func Cancel(task *Task) Effect {
+	return CancelEffect{task}
+}
+
+// Race creates an Effect that performs the given Effects at the same time, and only returns the result
+// of the first one to complete.
+func Race(effects map[string]Effect) Effect {
+	return RaceEffect{effects}
+}
+
+// Delay creates an Effect that waits for the given duration.
+func Delay(duration time.Duration) Effect {
+	return DelayEffect{duration}
+}
+
+// Select creates an Effect that allows the given Selector to pull data from the State.
+func Select(sel store.Selector) Effect {
+	return SelectEffect{sel}
+}
@@ -0,0 +1,71 @@
+package saga
+
+import "context"
+
+// A Harness runs a Saga for testing. Instead of performing the Effects, each one is returned from
+// Next() and the Saga waits until a result is given to Respond(...), so the test can step through the
+// Saga deterministically.
+// Ex)
+//	h := saga.NewHarness(loginFlow)
+//	effect, _ := h.Next()
+//	// ...check the effect is a saga.TakeEffect...
+//	h.Respond(loginAction{"user"}, nil)
+type Harness struct {
+	task    *Task
+	effects chan Effect
+	results chan harnessResult
+}
+
+// The result of an Effect, given to Harness.Respond(...).
+type harnessResult struct {
+	value interface{}
+	err   error
+}
+
+// Creates a Harness, and starts the given Saga on it.
+func NewHarness(saga Saga) *Harness {
+	h := &Harness{
+		effects: make(chan Effect),
+		results: make(chan harnessResult),
+	}
+	h.task = startTask(context.Background(), saga, h.perform)
+
+	return h
+}
+
+// Next waits for the Saga to perform an Effect, and returns it. Returns false if the Saga has returned
+// instead.
+func (h *Harness) Next() (Effect, bool) {
+	select {
+	case effect := <-h.effects:
+		return effect, true
+	case <-h.task.Done():
+		return nil, false
+	}
+}
+
+// Respond gives the result for the Effect returned from Next(), to the Saga.
+func (h *Harness) Respond(value interface{}, err error) {
+	h.results <- harnessResult{value, err}
+}
+
+// Task returns the Task the Saga is running on, so the test can cancel it or wait for it to return.
+func (h *Harness) Task() *Task {
+	return h.task
+}
+
+// Sends the given Effect to the test, and waits for its result.
+func (h *Harness) perform(t *Task, effect Effect) (interface{}, error) {
+	select {
+	case h.effects <- effect:
+	case <-t.ctx.Done():
+		return nil, t.ctx.Err()
+	}
+
+	select {
+	case result := <-h.results:
+		return result.value, result.err
+	case <-t.ctx.Done():
+		return nil, t.ctx.Err()
+	}
+}
@@ -0,0 +1,56 @@
+package saga
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHarnessStepsThroughEffects(t *testing.T) {
+	h := NewHarness(func(st *Task) error {
+		ping, err := st.Do(Take(TypeOf(testPing(0))))
+		if err != nil {
+			return err
+		}
+
+		_, err = st.Do(Put(testPong(ping.(testPing))))
+		return err
+	})
+
+	effect, hasEffect := h.Next()
+	if _, isTake := effect.(TakeEffect); !hasEffect || !isTake {
+		t.Fatal("The first effect should have been a TakeEffect, but was", effect)
+	}
+	h.Respond(testPing(3), nil)
+
+	effect, hasEffect = h.Next()
+	put, isPut := effect.(PutEffect)
+	if !hasEffect || !isPut {
+		t.Fatal("The second effect should have been a PutEffect, but was", effect)
+	}
+	if put.Action != testPong(3) {
+		t.Error("The saga should have put", testPong(3), "but put", put.Action)
+	}
+	h.Respond(nil, nil)
+
+	if _, hasEffect := h.Next(); hasEffect {
+		t.Error("The saga should not have any more effects")
+	}
+	if err := h.Task().Wait(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHarnessCanRespondWithErrors(t *testing.T) {
+	testErr := errors.New("test error")
+	h := NewHarness(func(st *Task) error {
+		_, err := st.Do(Delay(0))
+		return err
+	})
+
+	h.Next()
+	h.Respond(nil, testErr)
+
+	if err := h.Task().Wait(); err != testErr {
+		t.Error("The saga should have returned the error from the effect, but returned", err)
+	}
+}
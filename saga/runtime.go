@@ -0,0 +1,204 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nheyn/go-redux/middleware"
+	"github.com/nheyn/go-redux/store"
+	"sync"
+	"time"
+)
+
+// ErrNoStore is returned by Runtime.Run(...) if the Runtime's middleware has not been applied to a Store.
+var ErrNoStore = errors.New("saga: the runtime has not been applied to a store")
+
+// A Runtime runs Sagas for a Store. It must be added to the Store using middleware.Apply(...), so it can
+// see the dispatched actions. All of its Tasks are cancelled when the Store is shutdown.
+// Ex)
+//	rt := saga.NewRuntime()
+//	s := store.New(initialState, middleware.Apply(rt.Middleware))
+//	task, err := rt.Run(loginFlow)
+type Runtime struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	store  *store.Store
+	takers map[*taker]struct{}
+}
+
+// A TakeEffect that is waiting for an action.
+type taker struct {
+	pattern Pattern
+	action  chan interface{}
+}
+
+// Creates a new Runtime.
+func NewRuntime() *Runtime {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Runtime{
+		ctx:    ctx,
+		cancel: cancel,
+		takers: map[*taker]struct{}{},
+	}
+}
+
+// Middleware is a middleware generator, that should be passed to middleware.Apply(...), which will send
+// the dispatched actions to any Sagas that are waiting for them. The actions are sent once they have been
+// performed (see store.OnDispatched(...)), so a Saga will not see the actions that failed and can select
+// the State that includes them. The actions in a store.Batch are sent one at a time.
+func (rt *Runtime) Middleware(s *store.Store) middleware.Func {
+	rt.mu.Lock()
+	rt.store = s
+	rt.mu.Unlock()
+
+	go func() {
+		select {
+		case <-s.Done():
+			rt.cancel()
+		case <-rt.ctx.Done():
+		}
+	}()
+
+	store.OnDispatched(rt.emitChange)(s)
+
+	return func(ctx context.Context, action interface{}, next middleware.Next) error {
+		return next(ctx, action)
+	}
+}
+
+// Run starts the given Saga on a new Task.
+func (rt *Runtime) Run(saga Saga) (*Task, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.store == nil {
+		return nil, ErrNoStore
+	}
+
+	return startTask(rt.ctx, saga, rt.perform), nil
+}
+
+// Stop cancels all of the Tasks started by the Runtime.
+func (rt *Runtime) Stop() {
+	rt.cancel()
+}
+
+// Sends the action from the given Change, or each action if it is a store.Batch, to the takers.
+func (rt *Runtime) emitChange(change store.Change) {
+	actions, isBatch := change.Action.(store.Batch)
+	if !isBatch {
+		actions = store.Batch{change.Action}
+	}
+
+	for _, action := range actions {
+		rt.emit(action)
+	}
+}
+
+// Sends the given action to all of the takers that are waiting for it.
+func (rt *Runtime) emit(action interface{}) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	for t := range rt.takers {
+		if t.pattern(action) {
+			t.action <- action
+			delete(rt.takers, t)
+		}
+	}
+}
+
+// Performs the given Effect for the given Task.
+func (rt *Runtime) perform(t *Task, effect Effect) (interface{}, error) {
+	return rt.performWithContext(t.ctx, t, effect)
+}
+
+// Performs the given Effect for the given Task, it will stop if the given context is done.
+func (rt *Runtime) performWithContext(ctx context.Context, t *Task, effect Effect) (interface{}, error) {
+	switch e := effect.(type) {
+	case TakeEffect:
+		return rt.take(ctx, e.Pattern)
+	case PutEffect:
+		return nil, rt.store.Dispatch(ctx, e.Action)
+	case CallEffect:
+		return e.Fn(ctx)
+	case ForkEffect:
+		return startTask(t.ctx, e.Saga, rt.perform), nil
+	case CancelEffect:
+		e.Task.Cancel()
+		return nil, nil
+	case RaceEffect:
+		return rt.race(ctx, t, e.Effects)
+	case DelayEffect:
+		return nil, delay(ctx, e.Duration)
+	case SelectEffect:
+		return nil, rt.store.Select(e.Selector)
+	default:
+		return nil, fmt.Errorf("saga: unknown effect %T", effect)
+	}
+}
+
+// Waits for an action, that matches the given Pattern, to be dispatched.
+func (rt *Runtime) take(ctx context.Context, pattern Pattern) (interface{}, error) {
+	t := &taker{pattern, make(chan interface{}, 1)}
+
+	rt.mu.Lock()
+	rt.takers[t] = struct{}{}
+	rt.mu.Unlock()
+
+	select {
+	case action := <-t.action:
+		return action, nil
+	case <-ctx.Done():
+		rt.mu.Lock()
+		delete(rt.takers, t)
+		rt.mu.Unlock()
+
+		return nil, ctx.Err()
+	}
+}
+
+// Performs all the given Effects at the same time, and returns the result of the first to complete.
+func (rt *Runtime) race(ctx context.Context, t *Task, effects map[string]Effect) (interface{}, error) {
+	raceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type raceResult struct {
+		RaceResult
+		err error
+	}
+	results := make(chan raceResult, len(effects))
+	for key, effect := range effects {
+		go func(key string, effect Effect) {
+			value, err := rt.performWithContext(raceCtx, t, effect)
+			results <- raceResult{RaceResult{key, value}, err}
+		}(key, effect)
+	}
+
+	select {
+	case result := <-results:
+		if result.err != nil {
+			return nil, result.err
+		}
+
+		return result.RaceResult, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Waits for the given duration, or until the given context is done.
+func delay(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
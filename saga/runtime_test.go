@@ -0,0 +1,243 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"github.com/nheyn/go-redux/middleware"
+	"github.com/nheyn/go-redux/store"
+	"testing"
+	"time"
+)
+
+type testPing int
+
+type testPong int
+
+type testUpdater []interface{}
+
+func (u testUpdater) Update(_ context.Context, action interface{}) (store.Updater, error) {
+	return append(append(testUpdater{}, u...), action), nil
+}
+
+// An Updater that fails on negative pings.
+type testRejectUpdater struct{}
+
+func (u testRejectUpdater) Update(_ context.Context, action interface{}) (store.Updater, error) {
+	if ping, isPing := action.(testPing); isPing && ping < 0 {
+		return nil, errors.New("rejected ping")
+	}
+
+	return u, nil
+}
+
+type testSelector struct {
+	actions testUpdater
+}
+
+func (sel *testSelector) SelectFrom(st *store.State) {
+	sel.actions = (*st)["actions"].(testUpdater)
+}
+
+func newTestRuntime() (*Runtime, *store.Store) {
+	rt := NewRuntime()
+	s := store.New(store.State{"actions": testUpdater{}}, middleware.Apply(rt.Middleware))
+
+	return rt, s
+}
+
+func TestRuntimeCanTakeAndPut(t *testing.T) {
+	rt, s := newTestRuntime()
+	defer s.Close()
+
+	ponged := make(chan struct{})
+	task, err := rt.Run(func(st *Task) error {
+		ping, err := st.Do(Take(TypeOf(testPing(0))))
+		if err != nil {
+			return err
+		}
+
+		if _, err := st.Do(Put(testPong(ping.(testPing)))); err != nil {
+			return err
+		}
+
+		close(ponged)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for the saga to start taking
+	time.Sleep(time.Millisecond)
+	if err := s.Dispatch(context.Background(), "not a ping"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Dispatch(context.Background(), testPing(5)); err != nil {
+		t.Fatal(err)
+	}
+
+	<-ponged
+	if err := task.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	sel := &testSelector{}
+	s.Select(sel)
+	if last := sel.actions[len(sel.actions)-1]; last != testPong(5) {
+		t.Error("The saga should have dispatched", testPong(5), "but the last action was", last)
+	}
+}
+
+func TestRuntimeOnlyTakesCommittedActions(t *testing.T) {
+	rt := NewRuntime()
+	s := store.New(store.State{
+		"actions": testUpdater{},
+		"rejects": testRejectUpdater{},
+	}, middleware.Apply(rt.Middleware))
+	defer s.Close()
+
+	taken := make(chan interface{}, 1)
+	selected := &testSelector{}
+	task, err := rt.Run(func(st *Task) error {
+		ping, err := st.Do(Take(TypeOf(testPing(0))))
+		if err != nil {
+			return err
+		}
+		taken <- ping
+
+		_, err = st.Do(Select(selected))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for the saga to start taking
+	time.Sleep(time.Millisecond)
+	if err := s.Dispatch(context.Background(), testPing(-1)); err == nil {
+		t.Fatal("The negative ping should have been rejected")
+	}
+	if err := s.DispatchBatch(context.Background(), "not a ping", testPing(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := task.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if ping := <-taken; ping != testPing(2) {
+		t.Error("The saga should only take the committed ping, but took", ping)
+	}
+	if len(selected.actions) != 2 || selected.actions[1] != testPing(2) {
+		t.Error("The saga should select the State after the taken action was committed, but selected", selected.actions)
+	}
+}
+
+func TestRuntimeCanCallAndSelect(t *testing.T) {
+	rt, s := newTestRuntime()
+	defer s.Close()
+
+	task, err := rt.Run(func(st *Task) error {
+		value, err := st.Do(Call(func(_ context.Context) (interface{}, error) {
+			return "called", nil
+		}))
+		if err != nil {
+			return err
+		}
+		if value != "called" {
+			st.Cancel()
+		}
+
+		_, err = st.Do(Select(&testSelector{}))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := task.Wait(); err != nil {
+		t.Error("The saga should not have returned an error, but returned", err)
+	}
+}
+
+func TestRuntimeCanRace(t *testing.T) {
+	rt, s := newTestRuntime()
+	defer s.Close()
+
+	result := make(chan interface{}, 1)
+	task, err := rt.Run(func(st *Task) error {
+		value, err := st.Do(Race(map[string]Effect{
+			"ping":    Take(TypeOf(testPing(0))),
+			"timeout": Delay(time.Millisecond),
+		}))
+
+		result <- value
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := task.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if raceResult := (<-result).(RaceResult); raceResult.Key != "timeout" {
+		t.Error("The timeout should have won the race, but", raceResult.Key, "did")
+	}
+}
+
+func TestRuntimeCanForkAndCancel(t *testing.T) {
+	rt, s := newTestRuntime()
+	defer s.Close()
+
+	forked := make(chan *Task, 1)
+	task, err := rt.Run(func(st *Task) error {
+		child, err := st.Do(Fork(func(st *Task) error {
+			_, err := st.Do(Take(Any))
+			return err
+		}))
+		if err != nil {
+			return err
+		}
+		forked <- child.(*Task)
+
+		_, err = st.Do(Cancel(child.(*Task)))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := task.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (<-forked).Wait(); err != context.Canceled {
+		t.Error("The forked task should have been cancelled, but returned", err)
+	}
+}
+
+func TestRuntimeIsCancelledWhenTheStoreIsShutdown(t *testing.T) {
+	rt, s := newTestRuntime()
+
+	task, err := rt.Run(func(st *Task) error {
+		_, err := st.Do(Take(Any))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := task.Wait(); err != context.Canceled {
+		t.Error("The task should have been cancelled when the store was shutdown, but returned", err)
+	}
+}
+
+func TestRuntimeMustBeAppliedBeforeRunning(t *testing.T) {
+	rt := NewRuntime()
+
+	if _, err := rt.Run(func(_ *Task) error { return nil }); err != ErrNoStore {
+		t.Error("Running a saga before the runtime is applied should return ErrNoStore, but returned", err)
+	}
+}
@@ -0,0 +1,78 @@
+package saga
+
+import "context"
+
+// A Saga is a long running workflow, that performs side-effects by passing Effects to Task.Do(...).
+// Ex)
+//	func loginFlow(t *saga.Task) error {
+//		for {
+//			login, err := t.Do(saga.Take(saga.TypeOf(loginAction{})))
+//			if err != nil {
+//				return err
+//			}
+//
+//			// ...perform the login...
+//		}
+//	}
+type Saga func(*Task) error
+
+// A Task is a Saga that is running.
+type Task struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	perform func(*Task, Effect) (interface{}, error)
+	done    chan struct{}
+	err     error
+}
+
+// Creates a Task, that uses the given function to perform its Effects, and starts the given Saga on it.
+func startTask(ctx context.Context, saga Saga, perform func(*Task, Effect) (interface{}, error)) *Task {
+	taskCtx, cancel := context.WithCancel(ctx)
+	t := &Task{
+		ctx:     taskCtx,
+		cancel:  cancel,
+		perform: perform,
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(t.done)
+		defer cancel()
+
+		t.err = saga(t)
+	}()
+
+	return t
+}
+
+// Do performs the given Effect, and waits for its result. If the Task is cancelled, the error from the
+// Task's context is returned.
+func (t *Task) Do(effect Effect) (interface{}, error) {
+	if err := t.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return t.perform(t, effect)
+}
+
+// Context returns a context that is cancelled when the Task is.
+func (t *Task) Context() context.Context {
+	return t.ctx
+}
+
+// Cancel stops the Task, and any Tasks it has forked.
+func (t *Task) Cancel() {
+	t.cancel()
+}
+
+// Done returns a channel that is closed when the Saga has returned.
+func (t *Task) Done() <-chan struct{} {
+	return t.done
+}
+
+// Wait waits for the Saga to return, and returns its error.
+func (t *Task) Wait() error {
+	<-t.done
+
+	return t.err
+}
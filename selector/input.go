@@ -0,0 +1,62 @@
+package selector
+
+import "github.com/nheyn/go-redux/store"
+
+// An Input is a value that a Memo depends on, it is either an Updater in the State (see Key(...)) or
+// the value of another Memo.
+type Input interface {
+	// Selects the value of the Input from the given State, and a version that is used to check if the
+	// value has changed.
+	inputFrom(st *store.State) (value interface{}, version interface{})
+
+	// Checks if the value of the Input has changed, using the versions returned by inputFrom(...).
+	changed(prevVersion, currVersion interface{}) bool
+}
+
+// Key creates an Input for the Updater that has the given key in the State. The Input is changed when
+// store.Changed(...) reports that the Updater has changed.
+func Key(key interface{}) Input {
+	return keyInput{key}
+}
+
+// An Input that selects an Updater from the State.
+type keyInput struct {
+	key interface{}
+}
+
+func (in keyInput) inputFrom(st *store.State) (interface{}, interface{}) {
+	data := (*st)[in.key]
+
+	return data, data
+}
+
+func (in keyInput) changed(prevVersion, currVersion interface{}) bool {
+	prevData, _ := prevVersion.(store.Updater)
+	currData, _ := currVersion.(store.Updater)
+
+	return store.Changed(prevData, currData)
+}
+
+// Path creates an Input for the Updater at the given path of keys, in a State with sub-States created by
+// store.CombineUpdaters(...). The Input is changed when store.Changed(...) reports that the Updater has
+// changed.
+// Ex)
+//	profiles := selector.Path("users", "profiles")
+func Path(keys ...interface{}) Input {
+	return pathInput{keys}
+}
+
+// An Input that selects an Updater from a sub-State.
+type pathInput struct {
+	keys []interface{}
+}
+
+func (in pathInput) inputFrom(st *store.State) (interface{}, interface{}) {
+	data, _ := st.Lookup(in.keys...)
+
+	return data, data
+}
+
+func (in pathInput) changed(prevVersion, currVersion interface{}) bool {
+	return keyInput{}.changed(prevVersion, currVersion)
+}
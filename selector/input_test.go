@@ -0,0 +1,54 @@
+package selector
+
+import (
+	"github.com/nheyn/go-redux/store"
+	"testing"
+)
+
+func TestKeyInputSelectsTheUpdater(t *testing.T) {
+	st := store.State{"Updater 0": testUpdater(1)}
+
+	value, _ := Key("Updater 0").inputFrom(&st)
+	if value != testUpdater(1) {
+		t.Error("The Key input selected", value, "but should have selected", testUpdater(1))
+	}
+
+	value, _ = Key("Missing").inputFrom(&st)
+	if value != nil {
+		t.Error("The Key input should have selected nil for a missing key, but selected", value)
+	}
+}
+
+func TestKeyInputChecksIfTheUpdaterChanged(t *testing.T) {
+	in := Key("Updater 0")
+
+	if in.changed(testUpdater(1), testUpdater(1)) {
+		t.Error("The Key input should not have changed for equal Updaters")
+	}
+	if !in.changed(testUpdater(1), testUpdater(2)) {
+		t.Error("The Key input should have changed for different Updaters")
+	}
+	if !in.changed(nil, testUpdater(1)) {
+		t.Error("The Key input should have changed when the Updater was added")
+	}
+}
+
+func TestPathInputSelectsTheNestedUpdater(t *testing.T) {
+	st := store.State{
+		"Users": store.CombineUpdaters(store.State{"Updater 0": testUpdater(1)}),
+	}
+
+	value, _ := Path("Users", "Updater 0").inputFrom(&st)
+	if value != testUpdater(1) {
+		t.Error("The Path input selected", value, "but should have selected", testUpdater(1))
+	}
+
+	value, _ = Path("Users", "Missing").inputFrom(&st)
+	if value != nil {
+		t.Error("The Path input should have selected nil for a missing path, but selected", value)
+	}
+
+	if Path("Users", "Updater 0").changed(testUpdater(1), testUpdater(1)) {
+		t.Error("The Path input should not have changed for equal Updaters")
+	}
+}
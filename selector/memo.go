@@ -0,0 +1,136 @@
+package selector
+
+import (
+	"github.com/nheyn/go-redux/store"
+	"sync"
+)
+
+// A Memo is a Selector that derives a value from its Inputs. The value is only recomputed when one of
+// the Inputs has changed since the last time it was selected. A Memo can be used as an Input for another
+// Memo.
+// Ex)
+//	visibleTodos := selector.New(func(values ...interface{}) interface{} {
+//		return filterTodos(values[0].(todos), values[1].(filter))
+//	}, selector.Key("TODOS"), selector.Key("FILTER"))
+//
+//	todos, err := visibleTodos.Select(s)
+type Memo struct {
+	inputs  []Input
+	combine func(...interface{}) interface{}
+
+	mu         sync.Mutex
+	versions   []interface{}
+	value      interface{}
+	generation uint64
+	stats      Stats
+}
+
+// Stats contains information about how often a Memo was able to use its cached value.
+type Stats struct {
+	// The number of times the cached value was used.
+	Hits uint64
+	// The number of times the value was recomputed.
+	Misses uint64
+}
+
+// Creates a new Memo that will pass the values of the given Inputs, in order, to the given combine
+// function to compute its value.
+func New(combine func(values ...interface{}) interface{}, inputs ...Input) *Memo {
+	return &Memo{
+		inputs:  inputs,
+		combine: combine,
+	}
+}
+
+// Computes the value of the Memo for the given State, so it can be used as a store.Selector.
+func (m *Memo) SelectFrom(st *store.State) {
+	m.compute(st)
+}
+
+// Select returns the value of the Memo for the current State of the given Store.
+func (m *Memo) Select(s *store.Store) (interface{}, error) {
+	sel := &memoSelector{memo: m}
+	if err := s.Select(sel); err != nil {
+		return nil, err
+	}
+
+	return sel.value, nil
+}
+
+// Value returns the last value computed by the Memo.
+func (m *Memo) Value() interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.value
+}
+
+// Stats returns how often the Memo has used its cached value.
+func (m *Memo) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stats
+}
+
+// Reset removes the cached value of the Memo, so the next time it is selected it will be recomputed.
+func (m *Memo) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.versions = nil
+}
+
+// Gets the value for the Memo from the given State, it is only recomputed if one of the Inputs has
+// changed. Also returns the generation of the value, which changes every time it is recomputed.
+func (m *Memo) compute(st *store.State) (interface{}, uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values := make([]interface{}, len(m.inputs))
+	versions := make([]interface{}, len(m.inputs))
+	for i, in := range m.inputs {
+		values[i], versions[i] = in.inputFrom(st)
+	}
+
+	if m.versions != nil && !m.inputsChanged(versions) {
+		m.stats.Hits++
+		return m.value, m.generation
+	}
+
+	m.stats.Misses++
+	m.value = m.combine(values...)
+	m.versions = versions
+	m.generation++
+
+	return m.value, m.generation
+}
+
+// Checks if any of the given versions of the Inputs have changed since the value was last computed.
+func (m *Memo) inputsChanged(versions []interface{}) bool {
+	for i, in := range m.inputs {
+		if in.changed(m.versions[i], versions[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *Memo) inputFrom(st *store.State) (interface{}, interface{}) {
+	return m.compute(st)
+}
+
+func (m *Memo) changed(prevVersion, currVersion interface{}) bool {
+	return prevVersion != currVersion
+}
+
+// A store.Selector that saves the value of a Memo, so it can be returned from Memo.Select(...).
+type memoSelector struct {
+	memo  *Memo
+	value interface{}
+}
+
+func (sel *memoSelector) SelectFrom(st *store.State) {
+	sel.value, _ = sel.memo.compute(st)
+}
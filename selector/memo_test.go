@@ -0,0 +1,137 @@
+package selector
+
+import (
+	"context"
+	"github.com/nheyn/go-redux/store"
+	"testing"
+)
+
+func TestMemoCombinesItsInputs(t *testing.T) {
+	st := store.State{
+		"Updater 0": testUpdater(1),
+		"Updater 1": testUpdater(2),
+	}
+
+	memo := New(sumValues, Key("Updater 0"), Key("Updater 1"))
+	memo.SelectFrom(&st)
+
+	if memo.Value() != 3 {
+		t.Error("The Memo should have the value 3, but has", memo.Value())
+	}
+}
+
+func TestMemoOnlyRecomputesWhenInputsChange(t *testing.T) {
+	st := store.State{
+		"Updater 0": testUpdater(1),
+		"Updater 1": testUpdater(2),
+		"Unused":    testUpdater(3),
+	}
+
+	memo := New(sumValues, Key("Updater 0"), Key("Updater 1"))
+	memo.SelectFrom(&st)
+
+	st["Unused"] = testUpdater(4)
+	memo.SelectFrom(&st)
+
+	if stats := memo.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Error("The Memo should have 1 hit and 1 miss, but has", stats.Hits, "hits and", stats.Misses, "misses")
+	}
+
+	st["Updater 0"] = testUpdater(10)
+	memo.SelectFrom(&st)
+
+	if stats := memo.Stats(); stats.Misses != 2 {
+		t.Error("The Memo should have recomputed when an input changed, but has", stats.Misses, "misses")
+	}
+	if memo.Value() != 12 {
+		t.Error("The Memo should have the value 12, but has", memo.Value())
+	}
+
+	memo.Reset()
+	memo.SelectFrom(&st)
+
+	if stats := memo.Stats(); stats.Misses != 3 {
+		t.Error("The Memo should have recomputed after it was reset, but has", stats.Misses, "misses")
+	}
+}
+
+func TestMemoCanBeUsedAsAnInput(t *testing.T) {
+	st := store.State{
+		"Updater 0": testUpdater(1),
+		"Updater 1": testUpdater(2),
+		"Updater 2": testUpdater(3),
+	}
+
+	inner := New(sumValues, Key("Updater 0"), Key("Updater 1"))
+	outer := New(sumValues, inner, Key("Updater 2"))
+
+	outer.SelectFrom(&st)
+	if outer.Value() != 6 {
+		t.Error("The outer Memo should have the value 6, but has", outer.Value())
+	}
+
+	outer.SelectFrom(&st)
+	if stats := outer.Stats(); stats.Misses != 1 {
+		t.Error("The outer Memo should not have recomputed, but has", stats.Misses, "misses")
+	}
+
+	st["Updater 0"] = testUpdater(10)
+	outer.SelectFrom(&st)
+	if outer.Value() != 15 {
+		t.Error("The outer Memo should have the value 15, but has", outer.Value())
+	}
+}
+
+func TestMemoCanSelectFromAStore(t *testing.T) {
+	s := store.New(store.State{
+		"Updater 0": testUpdater(1),
+		"Updater 1": testUpdater(2),
+	})
+	defer s.Close()
+
+	memo := New(sumValues, Key("Updater 0"), Key("Updater 1"))
+
+	value, err := memo.Select(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 3 {
+		t.Error("The Memo should have selected the value 3, but selected", value)
+	}
+
+	if err := s.Dispatch(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err = memo.Select(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 13 {
+		t.Error("The Memo should have selected the value 13, but selected", value)
+	}
+}
+
+func sumValues(values ...interface{}) interface{} {
+	sum := 0
+	for _, value := range values {
+		switch v := value.(type) {
+		case testUpdater:
+			sum += int(v)
+		case int:
+			sum += v
+		}
+	}
+
+	return sum
+}
+
+type testUpdater int
+
+func (u testUpdater) Update(_ context.Context, action interface{}) (store.Updater, error) {
+	if amount, isInt := action.(int); isInt {
+		return u + testUpdater(amount), nil
+	}
+
+	return u, nil
+}
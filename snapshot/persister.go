@@ -0,0 +1,146 @@
+package snapshot
+
+import (
+	"github.com/nheyn/go-redux/store"
+	"sync"
+	"time"
+)
+
+// A Persister saves snapshots of the State of a Store, every N actions and/or on a timer.
+// Ex)
+//	registry := snapshot.NewRegistry(snapshot.JSON)
+//	registry.Register("counter", "COUNTER_STATE", counter(0))
+//	storage := &snapshot.FileStorage{Path: "state.json"}
+//
+//	initialState, _, err := snapshot.Load(registry, storage, defaultState)
+//	p := snapshot.NewPersister(registry, storage, snapshot.Every(10))
+//	s := store.New(initialState, p.Config)
+type Persister struct {
+	registry *Registry
+	storage  Storage
+	every    uint64
+	interval time.Duration
+
+	mu       sync.Mutex
+	state    store.State
+	sequence uint64
+	saved    uint64
+	err      error
+}
+
+// Creates a Persister that saves snapshots, serialized with the given Registry, to the given Storage.
+func NewPersister(registry *Registry, storage Storage, configs ...func(*Persister)) *Persister {
+	p := &Persister{registry: registry, storage: storage}
+	for _, config := range configs {
+		config(p)
+	}
+
+	return p
+}
+
+// A config function for a Persister that will save a snapshot after every given number of actions.
+func Every(actions uint64) func(*Persister) {
+	return func(p *Persister) {
+		p.every = actions
+	}
+}
+
+// A config function for a Persister that will save a snapshot, if the State has changed, every time the
+// given interval has passed.
+func Interval(interval time.Duration) func(*Persister) {
+	return func(p *Persister) {
+		p.interval = interval
+	}
+}
+
+// Config is a config function for store.New(...), it will make the Persister save snapshots of the Store.
+func (p *Persister) Config(s *store.Store) {
+	store.OnCommit(func(change store.Change) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.state = change.Current
+		p.sequence = change.Sequence
+		if p.every > 0 && p.sequence-p.saved >= p.every {
+			p.save()
+		}
+	})(s)
+
+	if p.interval > 0 {
+		go p.saveOnInterval(s.Done())
+	}
+}
+
+// Flush saves a snapshot, if the State has changed since the last one was saved.
+func (p *Persister) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.state == nil || p.sequence == p.saved {
+		return nil
+	}
+
+	return p.save()
+}
+
+// Err returns the last error that occurred while saving a snapshot.
+func (p *Persister) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err
+}
+
+// Saves a snapshot every interval, until the given channel is closed.
+func (p *Persister) saveOnInterval(done <-chan struct{}) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.Flush()
+		case <-done:
+			return
+		}
+	}
+}
+
+// Saves a snapshot of the last State. Must be called with the lock held.
+func (p *Persister) save() error {
+	data, err := p.registry.Marshal(p.state, p.sequence)
+	if err == nil {
+		err = p.storage.Save(data)
+	}
+
+	p.err = err
+	if err == nil {
+		p.saved = p.sequence
+	}
+
+	return err
+}
+
+// Load creates a State by replacing the Updaters in the given initial State with the ones from the
+// snapshot in the given Storage. It also returns the sequence the snapshot was saved at. If no snapshot
+// has been saved, the initial State is returned.
+func Load(registry *Registry, storage Storage, initialState store.State) (store.State, uint64, error) {
+	loadedState := store.State{}
+	loadedState.SelectFrom(&initialState)
+
+	data, err := storage.Load()
+	if err == ErrNoSnapshot {
+		return loadedState, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	snapState, sequence, err := registry.Unmarshal(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	loadedState.SelectFrom(&snapState)
+
+	return loadedState, sequence, nil
+}
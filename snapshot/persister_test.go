@@ -0,0 +1,88 @@
+package snapshot
+
+import (
+	"context"
+	"github.com/nheyn/go-redux/store"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPersisterSavesEveryNActions(t *testing.T) {
+	registry := newTestRegistry(JSON)
+	storage := &FileStorage{Path: filepath.Join(t.TempDir(), "snapshot.json")}
+	p := NewPersister(registry, storage, Every(2))
+
+	s := store.New(store.State{"COUNTER": testCounter(0)}, p.Config)
+	for i := 1; i <= 3; i++ {
+		if err := s.Dispatch(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Close()
+
+	loaded, sequence, err := Load(registry, storage, store.State{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sequence != 2 || loaded["COUNTER"] != testCounter(3) {
+		t.Error("The snapshot should have been saved after the second action, but was saved at", sequence)
+	}
+
+	if err := p.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, sequence, _ = Load(registry, storage, store.State{}); sequence != 3 || loaded["COUNTER"] != testCounter(6) {
+		t.Error("The snapshot should have been saved when flushed, but was saved at", sequence)
+	}
+}
+
+func TestPersisterSavesOnAnInterval(t *testing.T) {
+	registry := newTestRegistry(Gob)
+	storage := &FileStorage{Path: filepath.Join(t.TempDir(), "snapshot.gob")}
+	p := NewPersister(registry, storage, Interval(time.Millisecond))
+
+	s := store.New(store.State{"COUNTER": testCounter(0)}, p.Config)
+	defer s.Close()
+
+	if err := s.Dispatch(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if _, sequence, _ := Load(registry, storage, store.State{}); sequence == 1 {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("The snapshot should have been saved by the timer")
+}
+
+func TestLoadRehydratesTheInitialState(t *testing.T) {
+	registry := newTestRegistry(JSON)
+	storage := &FileStorage{Path: filepath.Join(t.TempDir(), "snapshot.json")}
+	initialState := store.State{
+		"COUNTER": testCounter(0),
+		"OTHER":   testCounter(1),
+	}
+
+	loaded, _, err := Load(registry, storage, initialState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded["COUNTER"] != testCounter(0) {
+		t.Error("Without a snapshot, the initial state should be loaded")
+	}
+
+	data, _ := registry.Marshal(store.State{"COUNTER": testCounter(10)}, 1)
+	storage.Save(data)
+
+	loaded, _, err = Load(registry, storage, initialState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded["COUNTER"] != testCounter(10) || loaded["OTHER"] != testCounter(1) {
+		t.Error("The snapshot should replace the Updaters in the initial state, but loaded", loaded)
+	}
+}
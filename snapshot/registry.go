@@ -0,0 +1,189 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nheyn/go-redux/store"
+	"reflect"
+)
+
+// ErrUnknownFormat is returned when a Registry is created with a Format that is not supported.
+var ErrUnknownFormat = errors.New("snapshot: unknown format")
+
+// A Format is the encoding used to serialize a State.
+type Format int
+
+const (
+	// Encode the State using encoding/json.
+	JSON Format = iota
+	// Encode the State using encoding/gob.
+	Gob
+)
+
+// A Registry contains the Updaters that can be saved in a snapshot. Only the Updaters that have been
+// registered are saved, so each Updater type must opt in by being registered with the key it is stored
+// under in the State.
+type Registry struct {
+	format Format
+	byKey  map[interface{}]registered
+	byName map[string]registered
+}
+
+// An Updater that has been registered.
+type registered struct {
+	name string
+	key  interface{}
+	typ  reflect.Type
+}
+
+// The serialized version of a State.
+type encodedSnapshot struct {
+	Sequence uint64
+	Updaters map[string][]byte
+}
+
+// The serialized version of a State, when using the JSON format.
+type jsonSnapshot struct {
+	Sequence uint64                     `json:"sequence"`
+	Updaters map[string]json.RawMessage `json:"updaters"`
+}
+
+// Creates a Registry that will serialize the State using the given Format.
+func NewRegistry(format Format) *Registry {
+	return &Registry{
+		format: format,
+		byKey:  map[interface{}]registered{},
+		byName: map[string]registered{},
+	}
+}
+
+// Register adds the Updater, with the given key in the State, to the Registry. The given name is used in
+// the snapshot instead of the key, and the example is used to get the concrete type of the Updater.
+// NOTE: The Updater type must be able to be encoded using the Format of the Registry, so it should only
+// have exported fields (or implement the marshaler interface for the Format).
+func (r *Registry) Register(name string, key interface{}, example store.Updater) {
+	reg := registered{name, key, reflect.TypeOf(example)}
+	r.byKey[key] = reg
+	r.byName[name] = reg
+}
+
+// Marshal serializes the registered Updaters in the given State, along with the sequence of the last
+// action that was performed on it.
+func (r *Registry) Marshal(st store.State, sequence uint64) ([]byte, error) {
+	encoded := map[string][]byte{}
+	for key, data := range st {
+		reg, isRegistered := r.byKey[key]
+		if !isRegistered {
+			continue
+		}
+		if reflect.TypeOf(data) != reg.typ {
+			return nil, fmt.Errorf("snapshot: %s has type %T, but %v was registered", reg.name, data, reg.typ)
+		}
+
+		encodedData, err := r.encode(data)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: unable to encode %s: %w", reg.name, err)
+		}
+		encoded[reg.name] = encodedData
+	}
+
+	switch r.format {
+	case JSON:
+		snap := jsonSnapshot{sequence, map[string]json.RawMessage{}}
+		for name, data := range encoded {
+			snap.Updaters[name] = data
+		}
+
+		return json.Marshal(snap)
+	case Gob:
+		var buf bytes.Buffer
+		err := gob.NewEncoder(&buf).Encode(encodedSnapshot{sequence, encoded})
+
+		return buf.Bytes(), err
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// Unmarshal creates a State, and the sequence, from the given serialized snapshot. Any Updaters in the
+// snapshot that have not been registered are ignored.
+func (r *Registry) Unmarshal(data []byte) (store.State, uint64, error) {
+	var snap encodedSnapshot
+	switch r.format {
+	case JSON:
+		var jsonSnap jsonSnapshot
+		if err := json.Unmarshal(data, &jsonSnap); err != nil {
+			return nil, 0, err
+		}
+
+		snap = encodedSnapshot{jsonSnap.Sequence, map[string][]byte{}}
+		for name, encodedData := range jsonSnap.Updaters {
+			snap.Updaters[name] = encodedData
+		}
+	case Gob:
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snap); err != nil {
+			return nil, 0, err
+		}
+	default:
+		return nil, 0, ErrUnknownFormat
+	}
+
+	st := store.State{}
+	for name, encodedData := range snap.Updaters {
+		reg, isRegistered := r.byName[name]
+		if !isRegistered {
+			continue
+		}
+
+		updater, err := r.decode(encodedData, reg.typ)
+		if err != nil {
+			return nil, 0, fmt.Errorf("snapshot: unable to decode %s: %w", name, err)
+		}
+		st[reg.key] = updater
+	}
+
+	return st, snap.Sequence, nil
+}
+
+// Encodes the given Updater using the Format of the Registry.
+func (r *Registry) encode(data store.Updater) ([]byte, error) {
+	switch r.format {
+	case JSON:
+		return json.Marshal(data)
+	case Gob:
+		var buf bytes.Buffer
+		err := gob.NewEncoder(&buf).EncodeValue(reflect.ValueOf(data))
+
+		return buf.Bytes(), err
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// Decodes an Updater, of the given type, using the Format of the Registry.
+func (r *Registry) decode(data []byte, typ reflect.Type) (store.Updater, error) {
+	ptr := reflect.New(typ)
+
+	var err error
+	switch r.format {
+	case JSON:
+		err = json.Unmarshal(data, ptr.Interface())
+	case Gob:
+		err = gob.NewDecoder(bytes.NewReader(data)).DecodeValue(ptr)
+	default:
+		err = ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updater, isUpdater := ptr.Elem().Interface().(store.Updater)
+	if !isUpdater {
+		return nil, fmt.Errorf("snapshot: %v is not an Updater", typ)
+	}
+
+	return updater, nil
+}
@@ -0,0 +1,73 @@
+package snapshot
+
+import (
+	"context"
+	"github.com/nheyn/go-redux/store"
+	"testing"
+)
+
+type testCounter int
+
+func (c testCounter) Update(_ context.Context, action interface{}) (store.Updater, error) {
+	if amount, isInt := action.(int); isInt {
+		return c + testCounter(amount), nil
+	}
+
+	return c, nil
+}
+
+type testProfile struct {
+	Name  string
+	Email string
+}
+
+func (p testProfile) Update(_ context.Context, _ interface{}) (store.Updater, error) {
+	return p, nil
+}
+
+func newTestRegistry(format Format) *Registry {
+	registry := NewRegistry(format)
+	registry.Register("counter", "COUNTER", testCounter(0))
+	registry.Register("profile", "PROFILE", testProfile{})
+
+	return registry
+}
+
+func TestRegistryCanRoundTripTheState(t *testing.T) {
+	for _, format := range []Format{JSON, Gob} {
+		registry := newTestRegistry(format)
+		st := store.State{
+			"COUNTER":      testCounter(5),
+			"PROFILE":      testProfile{"name", "email"},
+			"UNREGISTERED": testCounter(10),
+		}
+
+		data, err := registry.Marshal(st, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, sequence, err := registry.Unmarshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sequence != 7 {
+			t.Error("The snapshot should have the sequence 7, but has", sequence)
+		}
+		if loaded["COUNTER"] != st["COUNTER"] || loaded["PROFILE"] != st["PROFILE"] {
+			t.Error("The loaded state should match the saved state, but is", loaded)
+		}
+		if _, hasUnregistered := loaded["UNREGISTERED"]; hasUnregistered {
+			t.Error("Unregistered Updaters should not have been saved")
+		}
+	}
+}
+
+func TestRegistryChecksTheTypeOfUpdaters(t *testing.T) {
+	registry := newTestRegistry(JSON)
+
+	if _, err := registry.Marshal(store.State{"COUNTER": testProfile{}}, 0); err == nil {
+		t.Error("Marshaling an Updater with a different type than was registered should return an error")
+	}
+}
@@ -0,0 +1,66 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNoSnapshot is returned by Storage.Load() if no snapshot has been saved.
+var ErrNoSnapshot = errors.New("snapshot: no snapshot has been saved")
+
+// A Storage saves serialized snapshots.
+type Storage interface {
+	// Saves the given snapshot, replacing the previous one.
+	Save([]byte) error
+	// Loads the last snapshot that was saved, or returns ErrNoSnapshot.
+	Load() ([]byte, error)
+}
+
+// A FileStorage is a Storage that saves the snapshot to a file.
+type FileStorage struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Saves the given snapshot to the file. The snapshot is written to a temporary file first, so the
+// previous snapshot is not lost if the write fails.
+func (fs *FileStorage) Save(data []byte) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(fs.Path), filepath.Base(fs.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), fs.Path)
+}
+
+// Loads the snapshot from the file.
+func (fs *FileStorage) Load() ([]byte, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := os.ReadFile(fs.Path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoSnapshot
+	}
+
+	return data, err
+}
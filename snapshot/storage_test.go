@@ -0,0 +1,28 @@
+package snapshot
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStorageCanSaveAndLoad(t *testing.T) {
+	storage := &FileStorage{Path: filepath.Join(t.TempDir(), "snapshot")}
+
+	if _, err := storage.Load(); err != ErrNoSnapshot {
+		t.Error("Loading before a snapshot is saved should return ErrNoSnapshot, but returned", err)
+	}
+
+	for _, snap := range []string{"first snapshot", "second snapshot"} {
+		if err := storage.Save([]byte(snap)); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := storage.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != snap {
+			t.Error("The loaded snapshot should be", snap, "but is", string(data))
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package store
+
+import "fmt"
+
+// A Batch is the action of a Change that was caused by Store.DispatchBatch(...), it contains all of the
+// actions that were dispatched.
+type Batch []interface{}
+
+// A BatchError is returned from Store.DispatchBatch(...) when one of the actions returns an error.
+type BatchError struct {
+	// The index of the action, that returned the error, in the batch.
+	Index int
+	// The action that returned the error.
+	Action interface{}
+	// The error returned by the action.
+	Err error
+}
+
+func (err *BatchError) Error() string {
+	return fmt.Sprintf("store: action %d in batch failed: %v", err.Index, err.Err)
+}
+
+func (err *BatchError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStoreCanDispatchABatch(t *testing.T) {
+	st := New(State{
+		"Updater 0": testUpdater{},
+		"Updater 1": testUpdater{},
+	})
+	defer st.Close()
+
+	changes := make(chan Change, 2)
+	if _, err := st.SubscribeChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+
+	testActions := []interface{}{"Test action 0", "Test action 1", "Test action 2"}
+	if err := st.DispatchBatch(context.Background(), testActions...); err != nil {
+		t.Fatal(err)
+	}
+
+	change := <-changes
+	batch, isBatch := change.Action.(Batch)
+	if !isBatch || len(batch) != len(testActions) {
+		t.Error("The change should have a Batch with", len(testActions), "actions, but has", change.Action)
+	}
+	if change.Sequence != 1 {
+		t.Error("The batch should only update the sequence once, but it is", change.Sequence)
+	}
+
+	for key, data := range change.Current {
+		actions := data.(testUpdater).actions
+		if len(actions) != len(testActions) {
+			t.Error("The", key, "updater should have been passed", len(testActions), "actions, but has", len(actions))
+			continue
+		}
+
+		for i, action := range actions {
+			if action != testActions[i] {
+				t.Error("The", key, "updater was passed", action, "but should have been passed", testActions[i])
+			}
+		}
+	}
+
+	if len(changes) != 0 {
+		t.Error("Subscribers should only be updated once for a batch")
+	}
+}
+
+func TestStoreWillRollbackAFailedBatch(t *testing.T) {
+	st := New(State{
+		"Updater 0": testUpdater{},
+		"Updater 1": testFailOnUpdater{"Test action 2"},
+	})
+	defer st.Close()
+
+	err := st.DispatchBatch(context.Background(), "Test action 0", "Test action 1", "Test action 2")
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatal("The .DispatchBatch(...) method should have returned a *BatchError, but returned", err)
+	}
+	if batchErr.Index != 2 || batchErr.Action != "Test action 2" {
+		t.Error("The error should be for the action at index 2, but is for", batchErr.Action, "at index", batchErr.Index)
+	}
+	if !errors.Is(batchErr.Err, errTestFailOn) {
+		t.Error("The error should wrap the error returned by the Updater, but wraps", batchErr.Err)
+	}
+
+	currState := State{}
+	st.Select(&currState)
+
+	if actions := currState["Updater 0"].(testUpdater).actions; len(actions) != 0 {
+		t.Error("The Updater 0 updater should not have been passed any actions, but has", len(actions))
+	}
+}
+
+var errTestFailOn = errors.New("The testFailOnUpdater correctly return this error")
+
+type testFailOnUpdater struct {
+	failOn interface{}
+}
+
+func (u testFailOnUpdater) Update(_ context.Context, action interface{}) (Updater, error) {
+	if action == u.failOn {
+		return nil, errTestFailOn
+	}
+
+	return u, nil
+}
@@ -0,0 +1,101 @@
+package store
+
+import "reflect"
+
+// A Change describes an update to the State of a Store, that was caused by a dispatched action.
+type Change struct {
+	// The action that caused the Change.
+	Action interface{}
+	// The number of actions that have updated the Store, including this one. It is 0 if the action did not
+	// change any of the Updaters, so it was not committed.
+	Sequence uint64
+	// A snapshot of the State before the action was dispatched.
+	Previous State
+	// A snapshot of the State after the action was dispatched.
+	Current State
+	// The keys, in the State, of the Updaters that changed.
+	ChangedKeys KeySet
+	// The error for a partial commit, with the keys that were applied and the keys that failed (see
+	// PartialCommit(...)). It is nil if none of the Updaters failed.
+	Partial *DispatchError
+
+	store *Store
+}
+
+// A KeySet is a set of keys from a State.
+type KeySet map[interface{}]struct{}
+
+// Checks if the given key is in the set.
+func (keys KeySet) Has(key interface{}) bool {
+	_, hasKey := keys[key]
+
+	return hasKey
+}
+
+// Creates a KeySet with all the keys, where the Updater is different in the given States.
+func changedKeys(prevState, currState State) KeySet {
+	keys := KeySet{}
+	for key, currData := range currState {
+		prevData, hasPrev := prevState[key]
+		if !hasPrev || updaterChanged(prevData, currData) {
+			keys[key] = struct{}{}
+		}
+	}
+
+	for key := range prevState {
+		if _, hasCurr := currState[key]; !hasCurr {
+			keys[key] = struct{}{}
+		}
+	}
+
+	return keys
+}
+
+// Changed checks if the given Updaters are different, using the same rules that are used to find the
+// ChangedKeys of a Change.
+func Changed(prevData, currData Updater) bool {
+	return updaterChanged(prevData, currData)
+}
+
+// Checks if the given Updaters are different. If the previous Updater is an EqualUpdater it is used to
+// compare them, or if both are VersionedUpdaters their versions are compared. Otherwise if the Updaters
+// can not be compared (i.e. they contain slices or maps) they are treated as different.
+func updaterChanged(prevData, currData Updater) (changed bool) {
+	if prevData == nil || currData == nil {
+		return prevData != currData
+	}
+
+	if equalData, isEqual := prevData.(EqualUpdater); isEqual {
+		return !equalData.Equal(currData)
+	}
+
+	prevVersioned, isPrevVersioned := prevData.(VersionedUpdater)
+	currVersioned, isCurrVersioned := currData.(VersionedUpdater)
+	if isPrevVersioned && isCurrVersioned {
+		return prevVersioned.Version() != currVersioned.Version()
+	}
+
+	prevType := reflect.TypeOf(prevData)
+	if prevType != reflect.TypeOf(currData) || !prevType.Comparable() {
+		return true
+	}
+
+	// Comparable types can still panic if they contain interfaces with uncomparable values
+	defer func() {
+		if r := recover(); r != nil {
+			changed = true
+		}
+	}()
+
+	return prevData != currData
+}
+
+// CopyState creates a shallow copy of the given State, so keys can be added or removed without changing it.
+func CopyState(st State) State {
+	copied := make(State, len(st))
+	for key, data := range st {
+		copied[key] = data
+	}
+
+	return copied
+}
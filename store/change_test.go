@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"testing"
+)
+
+func TestChangedKeysFindsUpdatedKeys(t *testing.T) {
+	prevState := State{
+		"Same":    testValueUpdater("same"),
+		"Changed": testUpdater{name: "before"},
+		"Removed": testUpdater{name: "removed"},
+	}
+	currState := State{
+		"Same":    prevState["Same"],
+		"Changed": testUpdater{name: "after"},
+		"Added":   testUpdater{name: "added"},
+	}
+
+	keys := changedKeys(prevState, currState)
+
+	for _, key := range []string{"Changed", "Removed", "Added"} {
+		if !keys.Has(key) {
+			t.Error("The", key, "key should be in the changed keys")
+		}
+	}
+	if len(keys) != 3 {
+		t.Error("There should be 3 changed keys, but there are", len(keys))
+	}
+}
+
+func TestUpdaterChangedComparesUpdaters(t *testing.T) {
+	withActions := testUpdater{actions: []interface{}{"action"}}
+
+	tests := []struct {
+		name     string
+		prevData Updater
+		currData Updater
+		changed  bool
+	}{
+		{"equal values", testValueUpdater("a"), testValueUpdater("a"), false},
+		{"different values", testValueUpdater("a"), testValueUpdater("b"), true},
+		{"different types", testUpdater{}, testUpdaterError{}, true},
+		{"uncomparable values", withActions, withActions, true},
+		{"nil value", nil, testUpdater{}, true},
+		{"equal updaters", testEqualUpdater{1, "a"}, testEqualUpdater{1, "b"}, false},
+		{"unequal updaters", testEqualUpdater{1, "a"}, testEqualUpdater{2, "a"}, true},
+	}
+
+	for _, test := range tests {
+		if changed := updaterChanged(test.prevData, test.currData); changed != test.changed {
+			t.Error("For", test.name, "the Updater should have changed =", test.changed, "but was", changed)
+		}
+	}
+}
+
+type testValueUpdater string
+
+func (u testValueUpdater) Update(_ context.Context, _ interface{}) (Updater, error) {
+	return u, nil
+}
+
+type testEqualUpdater struct {
+	id   int
+	data string
+}
+
+func (u testEqualUpdater) Update(_ context.Context, _ interface{}) (Updater, error) {
+	return u, nil
+}
+
+func (u testEqualUpdater) Equal(other Updater) bool {
+	otherData, isEqualUpdater := other.(testEqualUpdater)
+
+	return isEqualUpdater && otherData.id == u.id
+}
+
+type testVersionedUpdater struct {
+	version uint64
+	data    []string
+}
+
+func (u testVersionedUpdater) Update(_ context.Context, _ interface{}) (Updater, error) {
+	return u, nil
+}
+
+func (u testVersionedUpdater) Version() uint64 {
+	return u.version
+}
+
+func TestVersionedUpdatersAreComparedByVersion(t *testing.T) {
+	prev := testVersionedUpdater{1, []string{"a"}}
+
+	if Changed(prev, testVersionedUpdater{1, []string{"b"}}) {
+		t.Error("Updaters with the same version should not have changed")
+	}
+	if !Changed(prev, testVersionedUpdater{2, []string{"a"}}) {
+		t.Error("Updaters with different versions should have changed")
+	}
+}
@@ -0,0 +1,84 @@
+package store
+
+import "context"
+
+// Creates an Updater that contains the given State, so a key in the State of a Store can hold a sub-State.
+// Each action is sent to all of the Updaters in the sub-State, and store.KeyFrom(...) will return their
+// full path of keys.
+// Ex)
+//	s := store.New(store.State{
+//		"users": store.CombineUpdaters(store.State{
+//			"profiles": profilesUpdater,
+//			"sessions": sessionsUpdater,
+//		}),
+//	})
+func CombineUpdaters(st State) Updater {
+	return combinedUpdater{CopyState(st)}
+}
+
+// An Updater that contains a sub-State.
+type combinedUpdater struct {
+	st State
+}
+
+func (combined combinedUpdater) Update(ctx context.Context, action interface{}) (Updater, error) {
+	// Sub-States are always updated as a whole, even when using partial commits
+	ctx = contextWithPartialCommit(ctx, false)
+
+	newState, err := performOnState(ctx, combined.st, action, performConfigFrom(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if len(newState) == 0 {
+		return combined, nil
+	}
+
+	updatedState := CopyState(combined.st)
+	for key, data := range newState {
+		updatedState[key] = data
+	}
+
+	return combinedUpdater{updatedState}, nil
+}
+
+func (combined combinedUpdater) Equal(other Updater) bool {
+	otherCombined, isCombined := other.(combinedUpdater)
+	if !isCombined || len(otherCombined.st) != len(combined.st) {
+		return false
+	}
+
+	return len(changedKeys(combined.st, otherCombined.st)) == 0
+}
+
+// Lookup gets the Updater at the given path of keys, where each key (except the last) is for an Updater
+// created by CombineUpdaters(...).
+func (st State) Lookup(path ...interface{}) (Updater, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+
+	data, hasData := st[path[0]]
+	if !hasData {
+		return nil, false
+	}
+	if len(path) == 1 {
+		return data, true
+	}
+
+	combined, isCombined := data.(combinedUpdater)
+	if !isCombined {
+		return nil, false
+	}
+
+	return combined.st.Lookup(path[1:]...)
+}
+
+// SubState returns a copy of the sub-State in the given Updater, if it was created by CombineUpdaters(...).
+func SubState(data Updater) (State, bool) {
+	combined, isCombined := data.(combinedUpdater)
+	if !isCombined {
+		return nil, false
+	}
+
+	return CopyState(combined.st), true
+}
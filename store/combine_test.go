@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type testPathUpdater struct {
+	key  interface{}
+	path []interface{}
+}
+
+func (u testPathUpdater) Update(ctx context.Context, _ interface{}) (Updater, error) {
+	key, _ := KeyFrom(ctx)
+	path, _ := KeyPathFrom(ctx)
+
+	return testPathUpdater{key, path}, nil
+}
+
+func TestCombinedUpdatersAreUpdated(t *testing.T) {
+	state := State{
+		"users": CombineUpdaters(State{
+			"profiles": testUpdater{},
+			"sessions": testUpdater{},
+		}),
+	}
+
+	updatedState, err := performUpdates(context.Background(), state, "Test action")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"profiles", "sessions"} {
+		data, hasData := updatedState.Lookup("users", key)
+		if !hasData {
+			t.Error("The nested Updater", key, "should be in the State")
+			continue
+		}
+		if !data.(testUpdater).didUpdate() {
+			t.Error("Update method not called on the nested Updater", key)
+		}
+	}
+}
+
+func TestCombinedUpdatersGetTheirKeyPath(t *testing.T) {
+	state := State{
+		"top": testPathUpdater{},
+		"users": CombineUpdaters(State{
+			"profiles": CombineUpdaters(State{"list": testPathUpdater{}}),
+		}),
+	}
+
+	updatedState, err := performUpdates(context.Background(), state, "Test action")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	top, _ := updatedState.Lookup("top")
+	if top.(testPathUpdater).key != "top" || !reflect.DeepEqual(top.(testPathUpdater).path, []interface{}{"top"}) {
+		t.Error("A top level Updater should get its key, but got", top)
+	}
+
+	nested, _ := updatedState.Lookup("users", "profiles", "list")
+	expectedPath := []interface{}{"users", "profiles", "list"}
+	if !reflect.DeepEqual(nested.(testPathUpdater).key, expectedPath) {
+		t.Error("A nested Updater should get its full path from KeyFrom, but got", nested.(testPathUpdater).key)
+	}
+	if !reflect.DeepEqual(nested.(testPathUpdater).path, expectedPath) {
+		t.Error("A nested Updater should get its full path from KeyPathFrom, but got", nested.(testPathUpdater).path)
+	}
+}
+
+func TestCombinedUpdatersReturnNestedErrors(t *testing.T) {
+	state := State{
+		"users": CombineUpdaters(State{"profiles": testUpdaterError{}}),
+	}
+
+	if _, err := performUpdates(context.Background(), state, "Test action"); err == nil {
+		t.Error("The error from the nested Updater should be returned")
+	}
+}
+
+func TestCombinedUpdatersCanBeCompared(t *testing.T) {
+	prev := CombineUpdaters(State{"a": testValueUpdater("1")})
+
+	if Changed(prev, CombineUpdaters(State{"a": testValueUpdater("1")})) {
+		t.Error("Combined Updaters with the same sub-State should not have changed")
+	}
+	if !Changed(prev, CombineUpdaters(State{"a": testValueUpdater("2")})) {
+		t.Error("Combined Updaters with a different sub-State should have changed")
+	}
+	if !Changed(prev, CombineUpdaters(State{"a": testValueUpdater("1"), "b": testValueUpdater("1")})) {
+		t.Error("Combined Updaters with a different number of keys should have changed")
+	}
+}
+
+func TestStateLookup(t *testing.T) {
+	state := State{
+		"flat":  testValueUpdater("flat"),
+		"users": CombineUpdaters(State{"profiles": testValueUpdater("profiles")}),
+	}
+
+	if data, _ := state.Lookup("flat"); data != testValueUpdater("flat") {
+		t.Error("Lookup should get a top level Updater, but got", data)
+	}
+	if data, _ := state.Lookup("users", "profiles"); data != testValueUpdater("profiles") {
+		t.Error("Lookup should get a nested Updater, but got", data)
+	}
+	if _, hasData := state.Lookup("flat", "missing"); hasData {
+		t.Error("Lookup should not find a path through an Updater that is not combined")
+	}
+	if _, hasData := state.Lookup("users", "missing"); hasData {
+		t.Error("Lookup should not find a missing key")
+	}
+
+	users, _ := state.Lookup("users")
+	if sub, isSub := SubState(users); !isSub || sub["profiles"] != testValueUpdater("profiles") {
+		t.Error("SubState should return the nested State, but got", sub)
+	}
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// The number of workers used to send an action to the Updaters one at a time.
+const sequentialWorkers = 1
+
+// The options for how the default PerformDispatch sends an action to the Updaters in a State.
+type performConfig struct {
+	// Selects the keys of the Updaters that each action is sent to, if nil actions are sent to all of them
+	router Router
+	// The number of goroutines used to update the Updaters, if 0 each Updater has its own goroutine
+	workers int
+	// The order the Updaters are started in, if nil it is random
+	less func(a, b interface{}) bool
+	// The longest an Updater can take, if 0 there is no limit
+	timeout time.Duration
+	// The timeouts for specific keys, these are only used for the keys of the Store's State
+	keyTimeouts map[interface{}]time.Duration
+	// If all of the Updaters should be run when one of them fails, instead of cancelling the rest
+	collectAll bool
+	// The functions to call after each Updater has handled an action
+	updateHooks []func(UpdateInfo)
+}
+
+// A config function for a Store that sends each action to the Updaters one at a time, on the goroutine
+// that performs the dispatch. Combined with OrderKeys(...) the Updaters are always called in the same
+// order, which is useful for debugging and tests.
+func Sequential(s *Store) {
+	s.perform.workers = sequentialWorkers
+}
+
+// A config function for a Store that sends each action to the Updaters using the given number of
+// goroutines.
+func WorkerPool(size int) func(*Store) {
+	return func(s *Store) {
+		s.perform.workers = size
+	}
+}
+
+// A config function for a Store that sends each action to all of the Updaters at the same time, each on
+// its own goroutine. This is the default.
+func Unbounded(s *Store) {
+	s.perform.workers = 0
+}
+
+// A config function for a Store that starts the Updaters in a fixed order, instead of the random order
+// of the State's map. The keys are sorted by their type, and then by their formatted value.
+// Ex)
+//	s := store.New(initialState, store.Sequential, store.OrderKeys)
+func OrderKeys(s *Store) {
+	s.perform.less = defaultKeyLess
+}
+
+// A config function for a Store that starts the Updaters in the order given by the less function.
+func OrderKeysBy(less func(a, b interface{}) bool) func(*Store) {
+	return func(s *Store) {
+		s.perform.less = less
+	}
+}
+
+// The default order for the keys of a State, it is deterministic for keys of any type but is not
+// always the natural order (i.e. 10 is before 9).
+func defaultKeyLess(a, b interface{}) bool {
+	aType, bType := fmt.Sprintf("%T", a), fmt.Sprintf("%T", b)
+	if aType != bType {
+		return aType < bType
+	}
+
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+// Gets the keys of the given State, in the order the Updaters should be started in.
+func (config performConfig) orderedKeys(st State) []interface{} {
+	keys := make([]interface{}, 0, len(st))
+	for key := range st {
+		keys = append(keys, key)
+	}
+
+	if config.less != nil {
+		sort.SliceStable(keys, func(i, j int) bool {
+			return config.less(keys[i], keys[j])
+		})
+	}
+
+	return keys
+}
+
+// The key for the performConfig in the context passed to the Updaters.
+type performConfigKey struct{}
+
+// Adds the given performConfig to the context, so combined Updaters can use the same strategy for
+// their sub-States. The Router and key timeouts are not added, because they only apply to the keys of
+// the Store's State.
+func contextWithPerformConfig(ctx context.Context, config performConfig) context.Context {
+	config.router = nil
+	config.keyTimeouts = nil
+
+	return context.WithValue(ctx, performConfigKey{}, config)
+}
+
+// Gets the performConfig from the given context.
+func performConfigFrom(ctx context.Context) performConfig {
+	config, _ := ctx.Value(performConfigKey{}).(performConfig)
+
+	return config
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// An Updater that records the order it was called in, and the number of Updaters that are running.
+type testOrderUpdater struct {
+	calls   *[]interface{}
+	mu      *sync.Mutex
+	running *int32
+	maxRan  *int32
+}
+
+func (u testOrderUpdater) Update(ctx context.Context, _ interface{}) (Updater, error) {
+	running := atomic.AddInt32(u.running, 1)
+	defer atomic.AddInt32(u.running, -1)
+	for {
+		maxRan := atomic.LoadInt32(u.maxRan)
+		if running <= maxRan || atomic.CompareAndSwapInt32(u.maxRan, maxRan, running) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+
+	key, _ := KeyFrom(ctx)
+	u.mu.Lock()
+	*u.calls = append(*u.calls, key)
+	u.mu.Unlock()
+
+	return u, nil
+}
+
+func newTestOrderState(keys ...interface{}) (State, *[]interface{}, *int32) {
+	calls := &[]interface{}{}
+	updater := testOrderUpdater{calls, &sync.Mutex{}, new(int32), new(int32)}
+
+	st := State{}
+	for _, key := range keys {
+		st[key] = updater
+	}
+
+	return st, calls, updater.maxRan
+}
+
+func TestSequentialStoreCallsUpdatersInOrder(t *testing.T) {
+	initialState, calls, maxRan := newTestOrderState("c", "a", "b", 1)
+	st := New(initialState, Sequential, OrderKeys)
+	defer st.Close()
+
+	if err := st.Dispatch(context.Background(), "Test action"); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []interface{}{1, "a", "b", "c"}; !reflect.DeepEqual(*calls, expected) {
+		t.Error("The Updaters should have been called in the order", expected, "but were called in", *calls)
+	}
+	if *maxRan != 1 {
+		t.Error("Only one Updater should run at a time, but", *maxRan, "ran at the same time")
+	}
+}
+
+func TestStoreCanOrderKeysBy(t *testing.T) {
+	initialState, calls, _ := newTestOrderState("a", "b", "c")
+	reverse := func(a, b interface{}) bool {
+		return a.(string) > b.(string)
+	}
+	st := New(initialState, Sequential, OrderKeysBy(reverse))
+	defer st.Close()
+
+	st.Dispatch(context.Background(), "Test action")
+
+	if expected := []interface{}{"c", "b", "a"}; !reflect.DeepEqual(*calls, expected) {
+		t.Error("The Updaters should have been called in the order", expected, "but were called in", *calls)
+	}
+}
+
+func TestWorkerPoolLimitsTheRunningUpdaters(t *testing.T) {
+	initialState, calls, maxRan := newTestOrderState("a", "b", "c", "d", "e", "f")
+	st := New(initialState, WorkerPool(2))
+	defer st.Close()
+
+	if err := st.Dispatch(context.Background(), "Test action"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*calls) != len(initialState) {
+		t.Error("All of the Updaters should have been called, but only", len(*calls), "were")
+	}
+	if *maxRan > 2 {
+		t.Error("At most 2 Updaters should run at the same time, but", *maxRan, "did")
+	}
+}
+
+func TestWorkerPoolReturnsUpdaterErrors(t *testing.T) {
+	st := New(State{
+		"Updater 0": testUpdater{},
+		"Updater 1": testUpdaterError{},
+		"Updater 2": testUpdater{},
+	}, WorkerPool(2))
+	defer st.Close()
+
+	if err := st.Dispatch(context.Background(), "Test action"); err == nil {
+		t.Error("The error from the Updater should have been returned")
+	}
+}
+
+func TestSequentialStoreAppliesToCombinedUpdaters(t *testing.T) {
+	nestedState, calls, _ := newTestOrderState("b", "a")
+	st := New(State{"nested": CombineUpdaters(nestedState)}, Sequential, OrderKeys)
+	defer st.Close()
+
+	st.Dispatch(context.Background(), "Test action")
+
+	expected := []interface{}{[]interface{}{"nested", "a"}, []interface{}{"nested", "b"}}
+	if !reflect.DeepEqual(*calls, expected) {
+		t.Error("The nested Updaters should have been called in the order", expected, "but were called in", *calls)
+	}
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A DispatchError is returned by the default PerformDispatch when one or more of the Updaters fail, or
+// the context of the dispatch is done before they finish. It can be used with errors.Is(...) and
+// errors.As(...) to check the errors from each of the Updaters (and the context).
+// Ex)
+//	var dispatchErr *store.DispatchError
+//	if errors.As(err, &dispatchErr) {
+//		for key, err := range dispatchErr.Errors {
+//			log.Println("updater", key, "failed:", err)
+//		}
+//	}
+type DispatchError struct {
+	// The action that was dispatched.
+	Action interface{}
+	// The errors returned by the Updaters that failed, by key.
+	Errors map[interface{}]error
+	// The keys of the Updaters that were cancelled (or never started) because another Updater failed.
+	Cancelled []interface{}
+	// The keys of the Updaters that did not fail, and were committed, when using PartialCommit(...).
+	Applied []interface{}
+	// The error from the context of the dispatch, if it was cancelled before all of the Updaters finished.
+	Err error
+}
+
+// Creates a *DispatchError with the given errors.
+func newDispatchError(action interface{}, errs map[interface{}]error, cancelled []interface{}) *DispatchError {
+	if cancelled == nil {
+		cancelled = []interface{}{}
+	}
+
+	return &DispatchError{Action: action, Errors: errs, Cancelled: cancelled}
+}
+
+func (err *DispatchError) Error() string {
+	messages := make([]string, 0, len(err.Errors))
+	for key, keyErr := range err.Errors {
+		messages = append(messages, fmt.Sprintf("%v: %v", key, keyErr))
+	}
+	sort.Strings(messages)
+
+	if err.Err != nil {
+		return fmt.Sprintf(
+			"store: dispatch of %T stopped with %d updater(s) cancelled: %v (%s)",
+			err.Action, len(err.Cancelled), err.Err, strings.Join(messages, "; "),
+		)
+	}
+	return fmt.Sprintf("store: %d updater(s) failed for %T (%s)", len(err.Errors), err.Action, strings.Join(messages, "; "))
+}
+
+func (err *DispatchError) Unwrap() []error {
+	errs := make([]error, 0, len(err.Errors)+1)
+	if err.Err != nil {
+		errs = append(errs, err.Err)
+	}
+	for _, keyErr := range err.Errors {
+		errs = append(errs, keyErr)
+	}
+
+	return errs
+}
+
+// A config function for a Store that runs all of the Updaters, even if one of them fails, so the
+// *DispatchError will contain all of their errors. The default is to cancel the other Updaters as soon
+// as one fails.
+func CollectAllErrors(s *Store) {
+	s.perform.collectAll = true
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errTestSecond = errors.New("The second test error")
+
+type testErrorUpdater struct {
+	err   error
+	delay time.Duration
+}
+
+func (u testErrorUpdater) Update(ctx context.Context, _ interface{}) (Updater, error) {
+	select {
+	case <-time.After(u.delay):
+		return nil, u.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestDispatchErrorHasTheFailedKey(t *testing.T) {
+	st := New(State{
+		"Updater 0": testUpdater{},
+		"Updater 1": testFailOnUpdater{"Test action"},
+	})
+	defer st.Close()
+
+	err := st.Dispatch(context.Background(), "Test action")
+
+	var dispatchErr *DispatchError
+	if !errors.As(err, &dispatchErr) {
+		t.Fatal("A *DispatchError should have been returned, but got", err)
+	}
+	if dispatchErr.Action != "Test action" {
+		t.Error("The *DispatchError should have the action, but has", dispatchErr.Action)
+	}
+	if len(dispatchErr.Errors) != 1 || dispatchErr.Errors["Updater 1"] != errTestFailOn {
+		t.Error("The *DispatchError should have the error for the failed key, but has", dispatchErr.Errors)
+	}
+	if !errors.Is(err, errTestFailOn) {
+		t.Error("The *DispatchError should wrap the error from the Updater")
+	}
+}
+
+func TestDispatchErrorHasTheCancelledKeys(t *testing.T) {
+	st := New(State{
+		"Updater 0": testErrorUpdater{errTestFailOn, 0},
+		"Updater 1": testErrorUpdater{errTestSecond, time.Hour},
+	})
+	defer st.Close()
+
+	var dispatchErr *DispatchError
+	if err := st.Dispatch(context.Background(), "Test action"); !errors.As(err, &dispatchErr) {
+		t.Fatal("A *DispatchError should have been returned, but got", err)
+	}
+	if len(dispatchErr.Errors) != 1 || dispatchErr.Errors["Updater 0"] != errTestFailOn {
+		t.Error("Only the first error should have been returned, but got", dispatchErr.Errors)
+	}
+	if len(dispatchErr.Cancelled) != 1 || dispatchErr.Cancelled[0] != "Updater 1" {
+		t.Error("The slow Updater should have been cancelled, but the cancelled keys are", dispatchErr.Cancelled)
+	}
+}
+
+func TestDispatchErrorHasTheKeysThatWereCancelledByTheContext(t *testing.T) {
+	// The Updater that ignores its context finishes after the dispatch is cancelled, when run in order
+	cancelledKeys := map[string]interface{}{"Sequential": "b", "Unbounded": "a"}
+	configs := map[string]func(*Store){"Sequential": Sequential, "Unbounded": Unbounded}
+
+	for name, concurrency := range configs {
+		st := New(State{
+			"a": testSlowUpdater{hang: true},
+			"b": testUpdater{},
+		}, concurrency, OrderKeys)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		err := st.Dispatch(ctx, "Test action")
+		cancel()
+		st.Close()
+
+		var dispatchErr *DispatchError
+		if !errors.As(err, &dispatchErr) {
+			t.Fatal(name, "A *DispatchError should have been returned, but got", err)
+		}
+		if dispatchErr.Err != context.DeadlineExceeded || !errors.Is(err, context.DeadlineExceeded) {
+			t.Error(name, "The *DispatchError should wrap the error from the context, but got", err)
+		}
+		if len(dispatchErr.Cancelled) != 1 || dispatchErr.Cancelled[0] != cancelledKeys[name] {
+			t.Error(name, "The unfinished Updater should have been cancelled, but the cancelled keys are", dispatchErr.Cancelled)
+		}
+	}
+}
+
+func TestSequentialDispatchErrorHasTheKeysThatDidNotRun(t *testing.T) {
+	st := New(State{
+		"a": testErrorUpdater{errTestFailOn, 0},
+		"b": testUpdater{},
+		"c": testUpdater{},
+	}, Sequential, OrderKeys)
+	defer st.Close()
+
+	var dispatchErr *DispatchError
+	if err := st.Dispatch(context.Background(), "Test action"); !errors.As(err, &dispatchErr) {
+		t.Fatal("A *DispatchError should have been returned, but got", err)
+	}
+	if len(dispatchErr.Cancelled) != 2 || dispatchErr.Cancelled[0] != "b" || dispatchErr.Cancelled[1] != "c" {
+		t.Error("The Updaters after the failed one should have been cancelled, but got", dispatchErr.Cancelled)
+	}
+}
+
+func TestStoreCanCollectAllErrors(t *testing.T) {
+	for _, configs := range [][]func(*Store){{CollectAllErrors}, {CollectAllErrors, Sequential}} {
+		st := New(State{
+			"Updater 0": testErrorUpdater{errTestFailOn, 0},
+			"Updater 1": testErrorUpdater{errTestSecond, 10 * time.Millisecond},
+			"Updater 2": testUpdater{},
+		}, configs...)
+
+		err := st.Dispatch(context.Background(), "Test action")
+
+		var dispatchErr *DispatchError
+		if !errors.As(err, &dispatchErr) {
+			t.Fatal("A *DispatchError should have been returned, but got", err)
+		}
+		if len(dispatchErr.Errors) != 2 || len(dispatchErr.Cancelled) != 0 {
+			t.Error("All of the errors should have been collected, but got", dispatchErr.Errors, dispatchErr.Cancelled)
+		}
+		if !errors.Is(err, errTestFailOn) || !errors.Is(err, errTestSecond) {
+			t.Error("The *DispatchError should wrap all of the errors")
+		}
+		if !strings.Contains(err.Error(), "Updater 0") || !strings.Contains(err.Error(), "Updater 1") {
+			t.Error("The error message should include the failed keys, but is", err.Error())
+		}
+
+		st.Close()
+	}
+}
@@ -0,0 +1,56 @@
+package store
+
+import "context"
+
+// A Future is the result of an action that was dispatched using Store.DispatchAsync(...).
+type Future struct {
+	done     chan struct{}
+	err      error
+	sequence uint64
+}
+
+// Creates a Future that has not been completed.
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+// Done returns a channel that is closed when the action has been performed.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for the action to be performed, and returns the error from the dispatch (see
+// Store.Dispatch(...)). If the given context is done first, its error is returned instead.
+func (f *Future) Wait(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sequence returns the sequence number of the Change that was caused by the action (see Change.Sequence),
+// or 0 if the action has not been performed, it returned an error or it did not change any of the Updaters.
+func (f *Future) Sequence() uint64 {
+	select {
+	case <-f.done:
+		return f.sequence
+	default:
+		return 0
+	}
+}
+
+// Waits for the action to be performed, and returns the error from the dispatch.
+func (f *Future) wait() error {
+	<-f.done
+
+	return f.err
+}
+
+// Sets the result of the Future, and marks it as done.
+func (f *Future) complete(err error, sequence uint64) {
+	f.err = err
+	f.sequence = sequence
+	close(f.done)
+}
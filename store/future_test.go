@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFutureWaitsForCompletion(t *testing.T) {
+	f := newFuture()
+	if f.Sequence() != 0 {
+		t.Error("A Future that is not done should have the sequence 0, but has", f.Sequence())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := f.Wait(ctx); err != context.DeadlineExceeded {
+		t.Error("Waiting on a Future that is not done should return the context error, but returned", err)
+	}
+
+	f.complete(nil, 5)
+
+	select {
+	case <-f.Done():
+	default:
+		t.Error("The done channel should be closed once the Future is completed")
+	}
+	if err := f.Wait(context.Background()); err != nil {
+		t.Error("The Future should not have an error, but has", err)
+	}
+	if f.Sequence() != 5 {
+		t.Error("The Future should have the sequence 5, but has", f.Sequence())
+	}
+}
+
+func TestStoreCanDispatchAsync(t *testing.T) {
+	st := New(State{"Updater 0": testUpdater{}}, QueueCapacity(10))
+	defer st.Close()
+
+	futures := []*Future{}
+	for i := 0; i < 10; i++ {
+		futures = append(futures, st.DispatchAsync(context.Background(), i))
+	}
+
+	for i, f := range futures {
+		if err := f.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		if f.Sequence() != uint64(i+1) {
+			t.Error("The action at index", i, "should have the sequence", i+1, "but has", f.Sequence())
+		}
+	}
+
+	currState := State{}
+	st.Select(&currState)
+
+	if actions := currState["Updater 0"].(testUpdater).actions; len(actions) != len(futures) {
+		t.Error("The Updater should have been passed", len(futures), "actions, but has", len(actions))
+	}
+}
+
+func TestStoreCanRejectWhenTheQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	blockDispatch := func(s *Store) {
+		performDispatch := s.PerformDispatch
+		s.PerformDispatch = func(ctx context.Context, st State, action interface{}) (State, error) {
+			<-release
+
+			return performDispatch(ctx, st, action)
+		}
+	}
+
+	st := New(State{"Updater 0": testUpdater{}}, QueueCapacity(1), RejectWhenQueueFull, blockDispatch)
+	defer st.Close()
+
+	futures := []*Future{}
+	rejected := 0
+	for i := 0; i < 5; i++ {
+		f := st.DispatchAsync(context.Background(), i)
+		if isDone(f) && f.err == ErrQueueFull {
+			rejected++
+			continue
+		}
+
+		futures = append(futures, f)
+	}
+	close(release)
+
+	// One action is being performed, and one is in the queue
+	if rejected < 3 {
+		t.Error("At least 3 actions should have been rejected, but", rejected, "were")
+	}
+	for _, f := range futures {
+		if err := f.Wait(context.Background()); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func isDone(f *Future) bool {
+	select {
+	case <-f.Done():
+		return true
+	default:
+		return false
+	}
+}
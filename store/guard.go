@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// An UpdaterPanicError is returned from a dispatch when an Updater panics, instead of crashing the
+// program.
+type UpdaterPanicError struct {
+	// The key of the Updater that panicked, see KeyFrom(...).
+	Key interface{}
+	// The action that was passed to the Updater.
+	Action interface{}
+	// The value passed to panic(...).
+	Value interface{}
+	// The stack trace of the goroutine when the Updater panicked.
+	Stack []byte
+}
+
+func (err *UpdaterPanicError) Error() string {
+	return fmt.Sprintf("store: updater %v panicked: %v", err.Key, err.Value)
+}
+
+// An UpdaterTimeoutError is returned from a dispatch when an Updater takes longer than its timeout, see
+// UpdaterTimeout(...). It wraps context.DeadlineExceeded.
+type UpdaterTimeoutError struct {
+	// The key of the Updater that timed out, see KeyFrom(...).
+	Key interface{}
+	// The action that was passed to the Updater.
+	Action interface{}
+	// The timeout of the Updater.
+	Timeout time.Duration
+}
+
+func (err *UpdaterTimeoutError) Error() string {
+	return fmt.Sprintf("store: updater %v did not finish within %v", err.Key, err.Timeout)
+}
+
+func (err *UpdaterTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// A config function for a Store that limits how long each Updater can take to handle an action. The
+// context passed to the Updater is cancelled after the timeout, and the dispatch returns an
+// *UpdaterTimeoutError. An Updater that ignores its context will keep running in the background, but
+// its result is discarded.
+func UpdaterTimeout(timeout time.Duration) func(*Store) {
+	return func(s *Store) {
+		s.perform.timeout = timeout
+	}
+}
+
+// A config function for a Store that limits how long the Updater, with the given key, can take to
+// handle an action. It overrides the timeout from UpdaterTimeout(...).
+// Ex)
+//	s := store.New(initialState, store.UpdaterTimeout(time.Second), store.UpdaterTimeoutFor("SEARCH", 5*time.Second))
+func UpdaterTimeoutFor(key interface{}, timeout time.Duration) func(*Store) {
+	return func(s *Store) {
+		if s.perform.keyTimeouts == nil {
+			s.perform.keyTimeouts = map[interface{}]time.Duration{}
+		}
+
+		s.perform.keyTimeouts[key] = timeout
+	}
+}
+
+// Sends the given action to the given Updater, see guardedUpdate(...), and calls the update hooks.
+func (config performConfig) update(ctx context.Context, key interface{}, data Updater, action interface{}) (Updater, error) {
+	ctx = contextWithKey(ctx, key)
+	if len(config.updateHooks) == 0 {
+		return config.guardedUpdate(ctx, key, data, action)
+	}
+
+	start := time.Now()
+	updatedData, err := config.guardedUpdate(ctx, key, data, action)
+
+	updaterKey, _ := KeyFrom(ctx)
+	info := UpdateInfo{updaterKey, action, time.Since(start), err}
+	for _, hook := range config.updateHooks {
+		hook(info)
+	}
+
+	return updatedData, err
+}
+
+// Sends the given action to the given Updater, any panic is returned as an *UpdaterPanicError. If the
+// Updater has a timeout, it is run on a separate goroutine so the timeout is enforced even if it ignores
+// its context. The given context must already have the key of the Updater.
+func (config performConfig) guardedUpdate(ctx context.Context, key interface{}, data Updater, action interface{}) (Updater, error) {
+	timeout, hasKeyTimeout := config.keyTimeouts[key]
+	if !hasKeyTimeout {
+		timeout = config.timeout
+	}
+	if timeout <= 0 {
+		return recoveredUpdate(ctx, data, action)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		data Updater
+		err  error
+	}
+	resultChan := make(chan result, 1)
+	go func() {
+		updatedData, err := recoveredUpdate(timeoutCtx, data, action)
+		resultChan <- result{updatedData, err}
+	}()
+
+	select {
+	case r := <-resultChan:
+		return r.data, r.err
+	case <-timeoutCtx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		updaterKey, _ := KeyFrom(ctx)
+		return nil, &UpdaterTimeoutError{updaterKey, action, timeout}
+	}
+}
+
+// Sends the given action to the given Updater, and returns an *UpdaterPanicError if it panics.
+func recoveredUpdate(ctx context.Context, data Updater, action interface{}) (updatedData Updater, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			key, _ := KeyFrom(ctx)
+			updatedData = nil
+			err = &UpdaterPanicError{key, action, r, debug.Stack()}
+		}
+	}()
+
+	return data.Update(ctx, action)
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testPanicUpdater struct{}
+
+func (u testPanicUpdater) Update(_ context.Context, _ interface{}) (Updater, error) {
+	panic("Test panic")
+}
+
+// An Updater that waits for its context, or ignores it if hang is true.
+type testSlowUpdater struct {
+	hang bool
+}
+
+func (u testSlowUpdater) Update(ctx context.Context, _ interface{}) (Updater, error) {
+	if u.hang {
+		time.Sleep(time.Second)
+		return u, nil
+	}
+
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestStoreRecoversFromPanickingUpdaters(t *testing.T) {
+	for _, configs := range [][]func(*Store){nil, {Sequential}, {UpdaterTimeout(time.Second)}} {
+		st := New(State{
+			"Updater 0": testUpdater{},
+			"Updater 1": testPanicUpdater{},
+		}, configs...)
+
+		err := st.Dispatch(context.Background(), "Test action")
+
+		var panicErr *UpdaterPanicError
+		if !errors.As(err, &panicErr) {
+			t.Error("An *UpdaterPanicError should have been returned, but got", err)
+		} else if panicErr.Key != "Updater 1" || panicErr.Action != "Test action" || panicErr.Value != "Test panic" {
+			t.Error("The *UpdaterPanicError should describe the panic, but got", panicErr)
+		} else if len(panicErr.Stack) == 0 {
+			t.Error("The *UpdaterPanicError should contain the stack")
+		}
+
+		if err := st.Dispatch(context.Background(), "Test action"); err == nil {
+			t.Error("The store should still be running after an Updater panicked")
+		}
+		st.Close()
+	}
+}
+
+func TestStoreCanRecoverFromPanicsDuringInit(t *testing.T) {
+	st := New(State{})
+	defer st.Close()
+
+	var panicErr *UpdaterPanicError
+	if err := st.AddUpdater(context.Background(), "Updater 0", testPanicUpdater{}); !errors.As(err, &panicErr) {
+		t.Error("An *UpdaterPanicError should have been returned, but got", err)
+	}
+}
+
+func TestStoreCanTimeoutUpdaters(t *testing.T) {
+	for _, hang := range []bool{false, true} {
+		st := New(State{
+			"Updater 0": testUpdater{},
+			"Updater 1": testSlowUpdater{hang},
+		}, UpdaterTimeout(10*time.Millisecond))
+
+		err := st.Dispatch(context.Background(), "Test action")
+
+		var timeoutErr *UpdaterTimeoutError
+		if !errors.As(err, &timeoutErr) || timeoutErr.Key != "Updater 1" || timeoutErr.Timeout != 10*time.Millisecond {
+			t.Error("An *UpdaterTimeoutError should have been returned, but got", err)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Error("The timeout error should wrap context.DeadlineExceeded")
+		}
+		st.Close()
+	}
+}
+
+func TestStoreCanTimeoutSpecificUpdaters(t *testing.T) {
+	st := New(
+		State{"Slow": testSlowUpdater{}},
+		UpdaterTimeout(time.Hour),
+		UpdaterTimeoutFor("Slow", 10*time.Millisecond),
+	)
+	defer st.Close()
+
+	var timeoutErr *UpdaterTimeoutError
+	if err := st.Dispatch(context.Background(), "Test action"); !errors.As(err, &timeoutErr) {
+		t.Error("The key timeout should have been used, but got", err)
+	}
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"errors"
+)
+
+// The key for the partial commit flag in the context of a dispatch.
+type partialCommitKey struct{}
+
+// A config function for a Store that commits the Updaters that did not fail, when other Updaters
+// return an error, instead of rolling back the whole action. The failed keys keep their current
+// Updater, and the dispatch returns a *DispatchError with the keys that were applied and the keys that
+// failed (see IsPartialCommit(...)). The Change for the action has the same error, and it is also passed to
+// the OnFailure(...) hooks after the State is updated. Batches, and the sub-States from CombineUpdaters(...),
+// are still rolled back as a whole.
+// Ex)
+//	err := s.Dispatch(ctx, action)
+//
+//	var dispatchErr *store.DispatchError
+//	if errors.As(err, &dispatchErr) && len(dispatchErr.Applied) > 0 {
+//		log.Println("only", dispatchErr.Applied, "were updated")
+//	}
+func PartialCommit(s *Store) {
+	s.partialCommit = true
+}
+
+// WithPartialCommit creates a context that will make a single dispatch commit the Updaters that did not
+// fail, even if the Store was not configured with PartialCommit(...).
+func WithPartialCommit(ctx context.Context) context.Context {
+	return contextWithPartialCommit(ctx, true)
+}
+
+// Sets if the dispatch using the given context should commit partial updates.
+func contextWithPartialCommit(ctx context.Context, partial bool) context.Context {
+	return context.WithValue(ctx, partialCommitKey{}, partial)
+}
+
+// Checks if the dispatch using the given context should commit partial updates.
+func partialCommitFrom(ctx context.Context) bool {
+	partial, _ := ctx.Value(partialCommitKey{}).(bool)
+
+	return partial
+}
+
+// IsPartialCommit checks if the given error, from a dispatch, is a *DispatchError where only some of the
+// Updaters failed so the rest were committed (see PartialCommit(...)).
+func IsPartialCommit(err error) bool {
+	var dispatchErr *DispatchError
+
+	return errors.As(err, &dispatchErr) && len(dispatchErr.Applied) > 0
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func newPartialTestStore(configs ...func(*Store)) *Store {
+	return New(State{
+		"Updater 0": testUpdater{},
+		"Updater 1": testFailOnUpdater{"Test action"},
+	}, configs...)
+}
+
+func TestStoreCanCommitPartialUpdates(t *testing.T) {
+	st := newPartialTestStore(PartialCommit)
+	defer st.Close()
+
+	changes := make(chan Change, 1)
+	if _, err := st.SubscribeChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+
+	f := st.DispatchAsync(context.Background(), "Test action")
+	err := f.Wait(context.Background())
+
+	var dispatchErr *DispatchError
+	if !errors.As(err, &dispatchErr) {
+		t.Fatal("A *DispatchError should have been returned, but got", err)
+	}
+	if len(dispatchErr.Applied) != 1 || dispatchErr.Applied[0] != "Updater 0" {
+		t.Error("The Updater that did not fail should have been applied, but the applied keys are", dispatchErr.Applied)
+	}
+	if len(dispatchErr.Errors) != 1 || !errors.Is(dispatchErr.Errors["Updater 1"], errTestFailOn) {
+		t.Error("The failed Updater should be in the errors, but the errors are", dispatchErr.Errors)
+	}
+	if f.Sequence() != 1 {
+		t.Error("The partial update should have been committed, but the sequence is", f.Sequence())
+	}
+
+	change := <-changes
+	if actions := change.Current["Updater 0"].(testUpdater).actions; len(actions) != 1 {
+		t.Error("The Updater that did not fail should have been updated, but has", actions)
+	}
+	if change.Current["Updater 1"] != (testFailOnUpdater{"Test action"}) {
+		t.Error("The Updater that failed should not have changed")
+	}
+}
+
+func TestStoreCanCommitPartialUpdatesForASingleDispatch(t *testing.T) {
+	st := newPartialTestStore()
+	defer st.Close()
+
+	if err := st.Dispatch(context.Background(), "Test action"); IsPartialCommit(err) {
+		t.Error("A partial update should not be committed by default, but got", err)
+	}
+
+	if err := st.Dispatch(WithPartialCommit(context.Background()), "Test action"); !IsPartialCommit(err) {
+		t.Error("The dispatch should have committed the partial update, but got", err)
+	}
+
+	currState := State{}
+	st.Select(&currState)
+	if actions := currState["Updater 0"].(testUpdater).actions; len(actions) != 1 {
+		t.Error("Only the partial dispatch should have updated the State, but the Updater has", actions)
+	}
+}
+
+func TestStoreWillNotCommitPartialBatches(t *testing.T) {
+	st := newPartialTestStore(PartialCommit)
+	defer st.Close()
+
+	err := st.DispatchBatch(context.Background(), "Other action", "Test action")
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || IsPartialCommit(err) {
+		t.Error("The batch should have been rolled back, but got", err)
+	}
+
+	currState := State{}
+	st.Select(&currState)
+	if actions := currState["Updater 0"].(testUpdater).actions; len(actions) != 0 {
+		t.Error("The batch should not have updated the State, but the Updater has", actions)
+	}
+}
+
+func TestStoreWillNotCommitWhenAllUpdatersFail(t *testing.T) {
+	failures := make(chan error, 1)
+	st := New(State{"Updater 0": testFailOnUpdater{"Test action"}}, PartialCommit, OnFailure(func(_ interface{}, err error) {
+		failures <- err
+	}))
+	defer st.Close()
+
+	if err := st.Dispatch(context.Background(), "Test action"); err == nil || IsPartialCommit(err) {
+		t.Error("The dispatch should have failed, but got", err)
+	}
+	if len(failures) != 1 {
+		t.Error("The failure hooks should have been called")
+	}
+}
+
+func TestStoreReportsPartialCommitsToTheHooks(t *testing.T) {
+	commits := make(chan Change, 1)
+	failures := make(chan error, 1)
+	st := newPartialTestStore(PartialCommit, OnCommit(func(change Change) {
+		commits <- change
+	}), OnFailure(func(_ interface{}, err error) {
+		failures <- err
+	}))
+	defer st.Close()
+
+	err := st.Dispatch(context.Background(), "Test action")
+	if !IsPartialCommit(err) {
+		t.Fatal("The dispatch should have been partially committed, but got", err)
+	}
+
+	change := <-commits
+	if change.Partial != err || len(change.Partial.Applied) != 1 || !change.ChangedKeys.Has("Updater 0") {
+		t.Error("The Change should have the *DispatchError for the partial commit, but has", change.Partial)
+	}
+	if failure := <-failures; failure != err {
+		t.Error("The failure hooks should have been called with the *DispatchError, but got", failure)
+	}
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrKeyExists is returned when an Updater is added to a State that already has its key.
+var ErrKeyExists = errors.New("store: the key is already in the state")
+
+// ErrKeyNotFound is returned when an Updater is removed from a State that does not have its key.
+var ErrKeyNotFound = errors.New("store: the key is not in the state")
+
+// An AddUpdaterAction is dispatched by AddUpdater(...), it adds the Updater to the State using the Key.
+type AddUpdaterAction struct {
+	Key     interface{}
+	Updater Updater
+}
+
+// A RemoveUpdaterAction is dispatched by RemoveUpdater(...), it removes the Updater with the Key from the
+// State.
+type RemoveUpdaterAction struct {
+	Key interface{}
+}
+
+// An InitAction is sent to an Updater when it is added to a Store, the State uses the Updater it returns.
+// It is only sent to the new Updater, not to the rest of the State.
+type InitAction struct {
+	Key interface{}
+}
+
+// AddUpdater adds the given Updater to the State of the Store, using the given key. It goes through the
+// action queue and the PerformDispatch of the Store (so middleware sees it), like the other dispatched
+// actions. The Updater is sent an InitAction before it is added, and the subscribers are sent a Change with
+// an AddUpdaterAction.
+// Ex)
+//	err := s.AddUpdater(ctx, "TODOS", todosUpdater)
+func (s *Store) AddUpdater(ctx context.Context, key interface{}, data Updater) error {
+	return s.Dispatch(ctx, AddUpdaterAction{key, data})
+}
+
+// RemoveUpdater removes the Updater with the given key from the State of the Store. Like AddUpdater(...),
+// it goes through the action queue and the PerformDispatch of the Store. The subscribers are sent a Change
+// with a RemoveUpdaterAction.
+func (s *Store) RemoveUpdater(ctx context.Context, key interface{}) error {
+	return s.Dispatch(ctx, RemoveUpdaterAction{key})
+}
+
+// Creates a State with the Updater from the given action, after it has been sent an InitAction using the
+// given performConfig.
+func addUpdater(ctx context.Context, st State, action AddUpdaterAction, config performConfig) (State, error) {
+	if _, hasKey := st[action.Key]; hasKey {
+		return nil, ErrKeyExists
+	}
+
+	initialized, err := config.update(ctx, action.Key, action.Updater, InitAction{action.Key})
+	if err != nil {
+		return nil, err
+	}
+
+	return State{action.Key: initialized}, nil
+}
+
+// Creates a State that will remove the key from the given action, see ReplaceState(...).
+func removeUpdater(st State, action RemoveUpdaterAction) (State, error) {
+	if _, hasKey := st[action.Key]; !hasKey {
+		return nil, ErrKeyNotFound
+	}
+
+	return State{action.Key: nil}, nil
+}
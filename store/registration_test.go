@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStoreCanAddAnUpdater(t *testing.T) {
+	st := New(State{"Updater 0": testValueUpdater("unchanged")})
+	defer st.Close()
+
+	changes := make(chan Change, 2)
+	if _, err := st.SubscribeChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := st.AddUpdater(context.Background(), "Updater 1", testUpdater{}); err != nil {
+		t.Fatal(err)
+	}
+
+	change := <-changes
+	if _, isAdd := change.Action.(AddUpdaterAction); !isAdd {
+		t.Error("The change should have an AddUpdaterAction, but has", change.Action)
+	}
+	if !change.ChangedKeys.Has("Updater 1") || change.ChangedKeys.Has("Updater 0") {
+		t.Error("Only the added key should have changed, but the changed keys are", change.ChangedKeys)
+	}
+
+	added := change.Current["Updater 1"].(testUpdater)
+	if len(added.actions) != 1 || added.actions[0] != (InitAction{"Updater 1"}) {
+		t.Error("The added Updater should have been sent an InitAction, but was sent", added.actions)
+	}
+
+	if err := st.Dispatch(context.Background(), "Test action"); err != nil {
+		t.Fatal(err)
+	}
+	if change := <-changes; len(change.Current["Updater 1"].(testUpdater).actions) != 2 {
+		t.Error("The added Updater should be sent the dispatched actions")
+	}
+}
+
+func TestStoreCanRemoveAnUpdater(t *testing.T) {
+	st := New(State{
+		"Updater 0": testUpdater{},
+		"Updater 1": testUpdater{},
+	})
+	defer st.Close()
+
+	changes := make(chan Change, 1)
+	if _, err := st.SubscribeChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := st.RemoveUpdater(context.Background(), "Updater 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	change := <-changes
+	if _, hasKey := change.Current["Updater 1"]; hasKey {
+		t.Error("The removed Updater should not be in the State")
+	}
+	if !change.ChangedKeys.Has("Updater 1") {
+		t.Error("The removed key should have changed")
+	}
+
+	st.Dispatch(context.Background(), "Test action")
+
+	currState := State{}
+	st.Select(&currState)
+	if _, hasKey := currState["Updater 1"]; hasKey || len(currState) != 1 {
+		t.Error("The removed Updater should not be added back by a dispatch, but the State is", currState)
+	}
+}
+
+func TestStoreWillNotReplaceAnUpdater(t *testing.T) {
+	st := New(State{"Updater 0": testUpdater{}})
+	defer st.Close()
+
+	if err := st.AddUpdater(context.Background(), "Updater 0", testUpdater{}); err != ErrKeyExists {
+		t.Error("Adding an existing key should return ErrKeyExists, but got", err)
+	}
+	if err := st.RemoveUpdater(context.Background(), "Missing"); err != ErrKeyNotFound {
+		t.Error("Removing a missing key should return ErrKeyNotFound, but got", err)
+	}
+}
+
+func TestStoreCanAddUpdatersInABatch(t *testing.T) {
+	st := New(State{"Updater 0": testUpdater{}})
+	defer st.Close()
+
+	err := st.DispatchBatch(
+		context.Background(),
+		AddUpdaterAction{"Updater 1", testUpdater{}},
+		"Test action",
+		RemoveUpdaterAction{"Updater 0"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	currState := State{}
+	st.Select(&currState)
+	if _, hasKey := currState["Updater 0"]; hasKey {
+		t.Error("The Updater should have been removed by the batch")
+	}
+	if added, _ := currState["Updater 1"].(testUpdater); len(added.actions) != 2 {
+		t.Error("The added Updater should have been sent the actions after it in the batch, but was sent", added.actions)
+	}
+}
+
+func TestStoreSendsRegistrationsThroughPerformDispatch(t *testing.T) {
+	performed := []interface{}{}
+	recordActions := func(s *Store) {
+		performDispatch := s.PerformDispatch
+		s.PerformDispatch = func(ctx context.Context, st State, action interface{}) (State, error) {
+			performed = append(performed, action)
+
+			return performDispatch(ctx, st, action)
+		}
+	}
+
+	st := New(State{"Updater 0": testUpdater{}}, recordActions)
+	defer st.Close()
+
+	if err := st.AddUpdater(context.Background(), "Updater 1", testValueUpdater("added")); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.RemoveUpdater(context.Background(), "Updater 0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(performed) != 2 || performed[0] != (AddUpdaterAction{"Updater 1", testValueUpdater("added")}) || performed[1] != (RemoveUpdaterAction{"Updater 0"}) {
+		t.Error("The registrations should have been passed to PerformDispatch, but it was passed", performed)
+	}
+}
+
+func TestStoreCanReplaceTheState(t *testing.T) {
+	replaceWith := State{"Updater 1": testValueUpdater("replaced")}
+	replaceState := func(s *Store) {
+		s.PerformDispatch = func(_ context.Context, st State, _ interface{}) (State, error) {
+			return ReplaceState(st, replaceWith), nil
+		}
+	}
+
+	st := New(State{"Updater 0": testValueUpdater("initial")}, replaceState)
+	defer st.Close()
+
+	if err := st.Dispatch(context.Background(), "Test action"); err != nil {
+		t.Fatal(err)
+	}
+
+	currState := State{}
+	st.Select(&currState)
+	if len(currState) != 1 || currState["Updater 1"] != testValueUpdater("replaced") {
+		t.Error("The State should have been replaced, but is", currState)
+	}
+}
@@ -0,0 +1,66 @@
+package store
+
+// An Updater that only handles some actions. The actions it does not handle are not sent to it, so its
+// current version is kept in the State.
+// Ex)
+//	func (todos Todos) Handles(action interface{}) bool {
+//		switch action.(type) {
+//		case AddTodo, RemoveTodo:
+//			return true
+//		default:
+//			return false
+//		}
+//	}
+type RoutedUpdater interface {
+	Updater
+
+	// Checks if the given action should be sent to this Updater.
+	Handles(action interface{}) bool
+}
+
+// A Router selects the keys, in the State, of the Updaters that an action should be sent to. If isRouted
+// is false, the action is sent to all of the Updaters.
+type Router func(action interface{}) (keys []interface{}, isRouted bool)
+
+// A config function for a Store that uses the given Router to select which Updaters are sent each
+// action. The rest of the Updaters are kept in the State without being updated. Any RoutedUpdaters are
+// still checked, even if an action is routed to them.
+// Ex)
+//	s := store.New(initialState, store.RouteActions(func(action interface{}) ([]interface{}, bool) {
+//		if userAction, isUserAction := action.(UserAction); isUserAction {
+//			return []interface{}{"USERS", userAction.ID}, true
+//		}
+//
+//		return nil, false
+//	}))
+func RouteActions(router Router) func(*Store) {
+	return func(s *Store) {
+		s.perform.router = router
+	}
+}
+
+// Selects the Updaters, in the given State, that the given action should be sent to.
+func routeAction(st State, action interface{}, router Router) State {
+	candidates := st
+	if router != nil {
+		if keys, isRouted := router(action); isRouted {
+			candidates = make(State, len(keys))
+			for _, key := range keys {
+				if data, hasData := st[key]; hasData {
+					candidates[key] = data
+				}
+			}
+		}
+	}
+
+	routed := make(State, len(candidates))
+	for key, data := range candidates {
+		if routedData, isRouted := data.(RoutedUpdater); isRouted && !routedData.Handles(action) {
+			continue
+		}
+
+		routed[key] = data
+	}
+
+	return routed
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+type testRoutedUpdater struct {
+	handles string
+	actions []interface{}
+}
+
+func (u testRoutedUpdater) Update(_ context.Context, action interface{}) (Updater, error) {
+	return testRoutedUpdater{u.handles, append(append([]interface{}{}, u.actions...), action)}, nil
+}
+
+func (u testRoutedUpdater) Handles(action interface{}) bool {
+	return action == u.handles
+}
+
+func TestRoutedUpdatersOnlyGetTheActionsTheyHandle(t *testing.T) {
+	st := New(State{
+		"Updater 0": testRoutedUpdater{handles: "Action 0"},
+		"Updater 1": testRoutedUpdater{handles: "Action 1"},
+		"Updater 2": testUpdater{},
+	})
+	defer st.Close()
+
+	st.Dispatch(context.Background(), "Action 0")
+	st.Dispatch(context.Background(), "Action 0")
+
+	currState := State{}
+	st.Select(&currState)
+	if actions := currState["Updater 0"].(testRoutedUpdater).actions; len(actions) != 2 {
+		t.Error("The Updater should have been sent the actions it handles, but was sent", actions)
+	}
+	if actions := currState["Updater 1"].(testRoutedUpdater).actions; len(actions) != 0 {
+		t.Error("The Updater should not have been sent the actions it does not handle, but was sent", actions)
+	}
+	if actions := currState["Updater 2"].(testUpdater).actions; len(actions) != 2 {
+		t.Error("An Updater that is not routed should be sent every action, but was sent", actions)
+	}
+}
+
+func TestStoreCanRouteActions(t *testing.T) {
+	router := func(action interface{}) ([]interface{}, bool) {
+		if action == "Routed action" {
+			return []interface{}{"Updater 1", "Missing"}, true
+		}
+
+		return nil, false
+	}
+	st := New(State{
+		"Updater 0": testUpdater{},
+		"Updater 1": testUpdater{},
+	}, RouteActions(router))
+	defer st.Close()
+
+	if err := st.Dispatch(context.Background(), "Routed action"); err != nil {
+		t.Fatal(err)
+	}
+	st.Dispatch(context.Background(), "Other action")
+
+	currState := State{}
+	st.Select(&currState)
+	if actions := currState["Updater 0"].(testUpdater).actions; len(actions) != 1 || actions[0] != "Other action" {
+		t.Error("The Updater should only have been sent the action that was not routed, but was sent", actions)
+	}
+	if actions := currState["Updater 1"].(testUpdater).actions; len(actions) != 2 {
+		t.Error("The Updater should have been sent both actions, but was sent", actions)
+	}
+	if _, hasKey := currState["Missing"]; hasKey {
+		t.Error("A routed key that is not in the State should not be added")
+	}
+}
+
+func TestCombinedUpdatersKeepUnroutedUpdaters(t *testing.T) {
+	state := State{
+		"users": CombineUpdaters(State{
+			"profiles": testRoutedUpdater{handles: "Action 0"},
+			"sessions": testRoutedUpdater{handles: "Action 1"},
+		}),
+	}
+
+	updatedState, err := performUpdates(context.Background(), state, "Action 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _ := updatedState.Lookup("users", "profiles"); len(data.(testRoutedUpdater).actions) != 1 {
+		t.Error("The nested Updater should have been sent the action it handles")
+	}
+	if data, hasData := updatedState.Lookup("users", "sessions"); !hasData || len(data.(testRoutedUpdater).actions) != 0 {
+		t.Error("The nested Updater that does not handle the action should be kept, but got", data)
+	}
+}
+
+// The number of Updaters in the State used by the routing benchmarks.
+const benchmarkStateSize = 500
+
+type benchmarkRoutedUpdater string
+
+func (u benchmarkRoutedUpdater) Update(_ context.Context, _ interface{}) (Updater, error) {
+	return u, nil
+}
+
+func (u benchmarkRoutedUpdater) Handles(action interface{}) bool {
+	return action == string(u)
+}
+
+func newBenchmarkState(routed bool) State {
+	st := State{}
+	for i := 0; i < benchmarkStateSize; i++ {
+		key := fmt.Sprint("Updater ", i)
+		if routed {
+			st[key] = benchmarkRoutedUpdater(key)
+		} else {
+			st[key] = testValueUpdater(key)
+		}
+	}
+
+	return st
+}
+
+func BenchmarkDispatchWithoutRouting(b *testing.B) {
+	st := New(newBenchmarkState(false))
+	defer st.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		st.Dispatch(context.Background(), "Updater 0")
+	}
+}
+
+func BenchmarkDispatchWithRoutedUpdaters(b *testing.B) {
+	st := New(newBenchmarkState(true))
+	defer st.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		st.Dispatch(context.Background(), "Updater 0")
+	}
+}
+
+func BenchmarkDispatchWithARouter(b *testing.B) {
+	router := func(action interface{}) ([]interface{}, bool) {
+		return []interface{}{action}, true
+	}
+	st := New(newBenchmarkState(false), RouteActions(router))
+	defer st.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		st.Dispatch(context.Background(), "Updater 0")
+	}
+}
@@ -13,52 +13,191 @@ func (selSt *State) SelectFrom(currSt *State) {
 }
 
 // A config function that will use make the given Store use the default state update function, which
-// is returned from getPerformUpdateFor(...).
+// is returned from getPerformUpdateFor(...). The actions that add or remove Updaters (see AddUpdater(...)
+// and RemoveUpdater(...)) change the keys of the State, instead of being sent to the Updaters.
 func defaultPeformDispatchConfig(s *Store) {
 	s.PerformDispatch = func(ctx context.Context, st State, action interface{}) (State, error) {
-		cancelableCtx, cancel := context.WithCancel(ctx)
-		defer cancel()
-
-		updateChan := make(chan keyedData, len(st))
-		errChan := make(chan error, len(st))
+		switch registration := action.(type) {
+		case AddUpdaterAction:
+			return addUpdater(ctx, st, registration, s.perform)
+		case RemoveUpdaterAction:
+			return removeUpdater(st, registration)
+		}
 
-		performUpdate := getPerformUpdateFor(cancelableCtx, action, updateChan, errChan)
+		return performOnState(ctx, st, action, s.perform)
+	}
+}
 
-		for key, data := range st {
-			go performUpdate(keyedData{key, data})
+// ReplaceState creates a State, that can be returned from a PerformDispatch function, which will replace
+// all of the Updaters in the given current State with the Updaters in the given replacement State. Any keys
+// that are not in the replacement State are removed.
+// Ex)
+//	s.PerformDispatch = func(ctx context.Context, st store.State, action interface{}) (store.State, error) {
+//		if reset, isReset := action.(ResetAction); isReset {
+//			return store.ReplaceState(st, reset.State), nil
+//		}
+//
+//		return performDispatch(ctx, st, action)
+//	}
+func ReplaceState(current, replacement State) State {
+	replaced := CopyState(replacement)
+	for key := range current {
+		if _, hasKey := replacement[key]; !hasKey {
+			replaced[key] = nil
 		}
+	}
+
+	return replaced
+}
 
-		newState := State{}
-		for len(newState) != len(st) {
-			select {
-			case update := <-updateChan:
-				newState[update.key] = update.data
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case err := <-errChan:
-				return nil, err
+// Sends the given action to each of the Updaters in the given State that it is routed to (see
+// RouteActions(...) and RoutedUpdater), using the strategy from the given performConfig, and returns a
+// State with the updated Updaters. The Updaters that the action is not routed to are not in the returned
+// State. If any of the Updaters fail, a *DispatchError is returned (along with the State of the Updaters
+// that did not fail, if the dispatch is using partial commits).
+func performOnState(ctx context.Context, st State, action interface{}, config performConfig) (State, error) {
+	st = routeAction(st, action, config.router)
+	keys := config.orderedKeys(st)
+	ctx = contextWithPerformConfig(ctx, config)
+
+	// When committing partial updates, all of the Updaters must run
+	partial := partialCommitFrom(ctx)
+	collectAll := config.collectAll || partial
+
+	// Avoid starting goroutines when the Updaters are run in order, or there is only a single Updater
+	if config.workers == sequentialWorkers || len(keys) == 1 {
+		newState := make(State, len(keys))
+		errs := map[interface{}]error{}
+		for i, key := range keys {
+			if err := ctx.Err(); err != nil {
+				return nil, cancelledError(err, action, keys[i:], errs)
+			}
+
+			updatedData, err := config.update(ctx, key, st[key], action)
+			if err != nil {
+				errs[key] = err
+				if !collectAll {
+					return nil, newDispatchError(action, errs, keys[i+1:])
+				}
+				continue
 			}
+			newState[key] = updatedData
 		}
 
+		if len(errs) > 0 {
+			return failedState(action, newState, errs, partial)
+		}
 		return newState, nil
 	}
+
+	cancelableCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updateChan := make(chan keyedData, len(st))
+	errChan := make(chan keyedError, len(st))
+
+	performUpdate := getPerformUpdateFor(cancelableCtx, action, config, updateChan, errChan)
+
+	if config.workers <= 0 || config.workers >= len(keys) {
+		for _, key := range keys {
+			go performUpdate(keyedData{key, st[key]})
+		}
+	} else {
+		keyChan := make(chan interface{}, len(keys))
+		for _, key := range keys {
+			keyChan <- key
+		}
+		close(keyChan)
+
+		for i := 0; i < config.workers; i++ {
+			go func() {
+				for key := range keyChan {
+					if cancelableCtx.Err() != nil {
+						return
+					}
+
+					performUpdate(keyedData{key, st[key]})
+				}
+			}()
+		}
+	}
+
+	newState := State{}
+	errs := map[interface{}]error{}
+	for len(newState)+len(errs) != len(st) {
+		select {
+		case update := <-updateChan:
+			newState[update.key] = update.data
+		case <-ctx.Done():
+			return nil, cancelledError(ctx.Err(), action, unfinishedKeys(keys, newState, errs), errs)
+		case keyedErr := <-errChan:
+			errs[keyedErr.key] = keyedErr.err
+			if collectAll {
+				continue
+			}
+
+			// Any Updaters that have not finished are cancelled
+			return nil, newDispatchError(action, errs, unfinishedKeys(keys, newState, errs))
+		}
+	}
+
+	if len(errs) > 0 {
+		return failedState(action, newState, errs, partial)
+	}
+	return newState, nil
+}
+
+// Creates the *DispatchError for the given errors. If partial is true, the given State, with the Updaters
+// that did not fail, is also returned so it can be committed.
+func failedState(action interface{}, newState State, errs map[interface{}]error, partial bool) (State, error) {
+	dispatchErr := newDispatchError(action, errs, nil)
+	if !partial || len(newState) == 0 {
+		return nil, dispatchErr
+	}
+
+	for key := range newState {
+		dispatchErr.Applied = append(dispatchErr.Applied, key)
+	}
+	return newState, dispatchErr
+}
+
+// Creates the *DispatchError for a dispatch that was stopped, with the given context error, before the
+// given keys finished.
+func cancelledError(ctxErr error, action interface{}, cancelled []interface{}, errs map[interface{}]error) *DispatchError {
+	dispatchErr := newDispatchError(action, errs, cancelled)
+	dispatchErr.Err = ctxErr
+
+	return dispatchErr
+}
+
+// Finds the given keys that are not in the given State, or errors.
+func unfinishedKeys(keys []interface{}, newState State, errs map[interface{}]error) []interface{} {
+	unfinished := []interface{}{}
+	for _, key := range keys {
+		_, isUpdated := newState[key]
+		_, isFailed := errs[key]
+		if !isUpdated && !isFailed {
+			unfinished = append(unfinished, key)
+		}
+	}
+
+	return unfinished
 }
 
-// Creates a function that will pefrom the update for the given action with the given context. The
-// given channeles will return all data and/or errors, so the returned function should be called
-// on a seperate goroutine.
+// Creates a function that will pefrom the update for the given action with the given context (see
+// performConfig.update(...)). The given channeles will return all data and/or errors, so the returned
+// function should be called on a seperate goroutine.
 func getPerformUpdateFor(
 	ctx context.Context,
 	action interface{},
+	config performConfig,
 	updateChan chan<- keyedData,
-	errChan chan<- error,
+	errChan chan<- keyedError,
 ) func(keyedData) {
 	return func(inital keyedData) {
-		ctxWithKey := contextWithKey(ctx, inital.key)
-
-		updatedData, err := inital.data.Update(ctxWithKey, action)
+		updatedData, err := config.update(ctx, inital.key, inital.data, action)
 		if err != nil {
-			errChan <- err
+			errChan <- keyedError{inital.key, err}
 			return
 		}
 
@@ -72,18 +211,41 @@ type keyedData struct {
 	data Updater
 }
 
+// An error returned by an Updater from a State, with it's assigned key.
+type keyedError struct {
+	key interface{}
+	err error
+}
+
 // The key for a Value that should be added to the ctx
 type contextKey int
 
-const keyKey contextKey = 0
+const (
+	keyKey contextKey = iota
+	pathKey
+)
 
-// Will add the given key to the context, so it can be accessed by KeyFrom(...).
+// Will add the given key to the context, so it can be accessed by KeyFrom(...). If the context already
+// has a key (i.e. for an Updater in a combined State) the key is added to the end of its path.
 func contextWithKey(ctx context.Context, key interface{}) context.Context {
-	return context.WithValue(ctx, keyKey, key)
+	parentPath, isNested := KeyPathFrom(ctx)
+
+	path := make([]interface{}, len(parentPath), len(parentPath)+1)
+	copy(path, parentPath)
+	path = append(path, key)
+
+	if isNested {
+		ctx = context.WithValue(ctx, keyKey, path)
+	} else {
+		ctx = context.WithValue(ctx, keyKey, key)
+	}
+
+	return context.WithValue(ctx, pathKey, path)
 }
 
 // When called in the .Update(...) method of an Updater, it will get the Store key for the
-// current Updater.
+// current Updater. For an Updater in a combined State (see CombineUpdaters(...)) it is the full
+// path of keys, as a []interface{}.
 func KeyFrom(ctx context.Context) (interface{}, bool) {
 	key := ctx.Value(keyKey)
 	if key == nil {
@@ -92,3 +254,12 @@ func KeyFrom(ctx context.Context) (interface{}, bool) {
 
 	return key, true
 }
+
+// When called in the .Update(...) method of an Updater, it will get the path of keys from the State of
+// the Store to the current Updater. For an Updater that is not in a combined State, the path only
+// contains its key.
+func KeyPathFrom(ctx context.Context) ([]interface{}, bool) {
+	path, hasPath := ctx.Value(pathKey).([]interface{})
+
+	return path, hasPath
+}
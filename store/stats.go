@@ -0,0 +1,72 @@
+package store
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats describes the load on a Store, see Store.Stats().
+type Stats struct {
+	// The number of actions waiting in the action queue.
+	QueuedActions int
+	// The number of actions the action queue can hold, see QueueCapacity(...).
+	QueueCapacity int
+	// The number of subscribers.
+	Subscribers int
+	// The number of updates that are queued for the subscribers, but have not been delivered.
+	SubscriberBacklog int
+	// The number of updates that were dropped since the Store was created, including the updates for
+	// subscribers that have been removed (see Subscription.Dropped()).
+	DroppedUpdates uint64
+}
+
+// The counters for the subscribers of a Store, they are updated by each Subscription so Stats() does not
+// need to wait for the goroutine that sends the updates.
+type subscriberCounters struct {
+	dropped     uint64
+	subscribers int64
+	backlog     int64
+}
+
+// Stats returns the current load on the Store. It does not wait for the subscribers, so it can be called
+// even when a subscriber is not receiving its updates.
+func (s *Store) Stats() (Stats, error) {
+	if s.isClosing() {
+		return Stats{}, ErrStoreClosed
+	}
+
+	// An update can be taken from a queue before it is counted, so the backlog can briefly be negative
+	backlog := atomic.LoadInt64(&s.counters.backlog)
+	if backlog < 0 {
+		backlog = 0
+	}
+
+	return Stats{
+		QueuedActions:     len(s.actionQueue),
+		QueueCapacity:     cap(s.actionQueue),
+		Subscribers:       int(atomic.LoadInt64(&s.counters.subscribers)),
+		SubscriberBacklog: int(backlog),
+		DroppedUpdates:    atomic.LoadUint64(&s.counters.dropped),
+	}, nil
+}
+
+// UpdateInfo describes a call to the .Update(...) method of an Updater, it is passed to the OnUpdate hooks.
+type UpdateInfo struct {
+	// The key of the Updater, see KeyFrom(...).
+	Key interface{}
+	// The action passed to the Updater.
+	Action interface{}
+	// How long the Updater took.
+	Duration time.Duration
+	// The error returned by the Updater, if any.
+	Err error
+}
+
+// A config function that will make the Store call the given function every time one of its Updaters
+// handles an action (when using the default PerformDispatch). The function is called on the goroutine
+// that ran the Updater, so it may be called from multiple goroutines at the same time.
+func OnUpdate(hook func(UpdateInfo)) func(*Store) {
+	return func(s *Store) {
+		s.perform.updateHooks = append(s.perform.updateHooks, hook)
+	}
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStoreStats(t *testing.T) {
+	st := New(State{"Updater 0": testUpdater{}}, QueueCapacity(5))
+	defer st.Close()
+
+	sub := make(chan Change)
+	subscription, err := st.SubscribeChanges(sub, Buffered(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Dispatch(context.Background(), "Test action 0")
+	st.Dispatch(context.Background(), "Test action 1")
+
+	// Subscribing is done after the updates have been queued for the first subscriber
+	if _, err := st.Subscribe(make(chan *Store)); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := st.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.QueueCapacity != 5 || stats.QueuedActions != 0 {
+		t.Error("The action queue should be empty with a capacity of 5, but got", stats)
+	}
+	if stats.Subscribers != 2 {
+		t.Error("There should be 2 subscribers, but there are", stats.Subscribers)
+	}
+	// The delivery goroutine may have already taken one of the updates, while it waits for the subscriber
+	if stats.SubscriberBacklog < 1 || stats.SubscriberBacklog > 2 {
+		t.Error("1 or 2 updates should be queued for the subscriber, but there are", stats.SubscriberBacklog)
+	}
+
+	if !subscription.Unsubscribe() {
+		t.Fatal("The subscriber should have been unsubscribed")
+	}
+	stats, err = st.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Subscribers != 1 || stats.SubscriberBacklog != 0 {
+		t.Error("There should be 1 subscriber and no backlog after unsubscribing, but got", stats)
+	}
+
+	st.Close()
+	if _, err := st.Stats(); err != ErrStoreClosed {
+		t.Error("ErrStoreClosed should be returned after the store is closed, but got", err)
+	}
+}
+
+func TestStoreStatsDoNotWaitForStalledSubscribers(t *testing.T) {
+	st := New(State{"Updater 0": testUpdater{}})
+	defer st.Shutdown(context.Background())
+
+	stalledSub := make(chan *Store)
+	if _, err := st.Subscribe(stalledSub); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Dispatch(context.Background(), "Test action"); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan Stats, 1)
+	go func() {
+		stats, _ := st.Stats()
+		done <- stats
+	}()
+
+	select {
+	case stats := <-done:
+		if stats.Subscribers != 1 {
+			t.Error("There should be 1 subscriber, but there are", stats.Subscribers)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The stats should not wait for the stalled subscriber")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	st.Shutdown(ctx)
+}
+
+func TestStoreStatsKeepDroppedUpdatesForRemovedSubscribers(t *testing.T) {
+	st := New(State{"Updater 0": testUpdater{}})
+	defer st.Close()
+
+	sub := make(chan *Store)
+	subscription, err := st.SubscribeWith(sub, Buffered(1), DropNewest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		st.Dispatch(context.Background(), i)
+	}
+	// Unsubscribing is done after the updates have been published, and removes the queued updates
+	subscription.Unsubscribe()
+	dropped := subscription.Dropped()
+
+	stats, err := st.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dropped == 0 || stats.DroppedUpdates != dropped {
+		t.Error("The Store should still count the", dropped, "dropped updates, but counted", stats.DroppedUpdates)
+	}
+	if stats.Subscribers != 0 || stats.SubscriberBacklog != 0 {
+		t.Error("There should be no subscribers or backlog after unsubscribing, but got", stats)
+	}
+}
+
+func TestStoreCallsUpdateHooks(t *testing.T) {
+	var mu sync.Mutex
+	infos := []UpdateInfo{}
+	st := New(State{
+		"Updater 0": testUpdater{},
+		"Updater 1": testUpdaterError{},
+	}, CollectAllErrors, OnUpdate(func(info UpdateInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		infos = append(infos, info)
+	}))
+	defer st.Close()
+
+	st.Dispatch(context.Background(), "Test action")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(infos) != 2 {
+		t.Fatal("The hook should have been called for each Updater, but was called", len(infos), "times")
+	}
+	for _, info := range infos {
+		if info.Action != "Test action" {
+			t.Error("The hook should have been passed the action, but was passed", info.Action)
+		}
+		if info.Key == "Updater 1" && info.Err == nil {
+			t.Error("The hook should have been passed the error from the Updater")
+		}
+	}
+}
@@ -1,8 +1,26 @@
 package store
 
-import "context"
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
 
-// A PerformDispatch function is used to dispatch the given action to given State.
+// ErrStoreClosed is returned by the methods of a Store after Shutdown(...) or Close() has been called.
+var ErrStoreClosed = errors.New("store: the store is closed")
+
+// ErrAlreadySubscribed is returned when a channel is subscribed to a Store that it is already subscribed to.
+var ErrAlreadySubscribed = errors.New("store: the channel is already subscribed")
+
+// ErrQueueFull is returned when an action is dispatched while the action queue is full, if the Store was
+// configured with RejectWhenQueueFull(...).
+var ErrQueueFull = errors.New("store: the action queue is full")
+
+// A PerformDispatch function is used to dispatch the given action to given State. The returned State only
+// needs to have the Updaters that were updated, a key with a nil Updater is removed from the State. When the
+// dispatch is using partial commits, it can return the State of the Updaters that did not fail with a
+// *DispatchError.
 type PerformDispatch func(context.Context, State, interface{}) (State, error)
 
 // A Store keeps track of data in a State, and "attempts to make state mutations predictable".
@@ -11,6 +29,41 @@ type Store struct {
 	actionQueue       chan queuedAction
 	accessState       chan func(*State)
 	accessSubscribers chan func(*subscriberSet)
+
+	closeMu   sync.RWMutex
+	closeOnce sync.Once
+	pending   sync.WaitGroup
+	routines  sync.WaitGroup
+	closing   chan struct{}
+	stopped   chan struct{}
+	done      chan struct{}
+
+	// Closed when a call to Shutdown(...) stops waiting, so subscribers that are not receiving updates can
+	// not stop the Store from shutting down
+	abandoned   chan struct{}
+	abandonOnce sync.Once
+
+	// Updated by the Subscriptions, so Stats() can be read without the subscriber goroutine
+	counters *subscriberCounters
+
+	// If actions should be rejected, instead of waiting, when the action queue is full
+	rejectWhenFull bool
+
+	// How the default PerformDispatch sends actions to the Updaters
+	perform performConfig
+
+	// If the Updaters that did not fail should be committed, when others fail
+	partialCommit bool
+
+	// The functions to call before and after the State is updated, after an action is performed, or when an
+	// action returns an error
+	beforeCommitHooks []func(Change) error
+	commitHooks       []func(Change)
+	dispatchedHooks   []func(Change)
+	failureHooks      []func(interface{}, error)
+
+	// The number of actions that have updated the State, only used by the listenForActions goroutine
+	sequence uint64
 }
 
 // Creates a new Store that start with the given state.
@@ -20,6 +73,11 @@ func New(initialState State, configs ...func(*Store)) *Store {
 		actionQueue:       make(chan queuedAction),
 		accessState:       make(chan func(*State)),
 		accessSubscribers: make(chan func(*subscriberSet)),
+		closing:           make(chan struct{}),
+		stopped:           make(chan struct{}),
+		done:              make(chan struct{}),
+		abandoned:         make(chan struct{}),
+		counters:          &subscriberCounters{},
 	}
 
 	// Configure store
@@ -29,6 +87,7 @@ func New(initialState State, configs ...func(*Store)) *Store {
 	}
 
 	// Start store
+	s.routines.Add(3)
 	go s.trackState(initialState)
 	go s.listenForActions()
 	go s.trackSubscribers()
@@ -37,111 +96,498 @@ func New(initialState State, configs ...func(*Store)) *Store {
 }
 
 // Dispatches the given action to all of the Updaters in the state of the Store. If an error is
-// returned, then the State will not not change (even for the Updaters that had already completed).
+// returned, then the State will not not change (even for the Updaters that had already completed), unless
+// the Store was configured with PartialCommit(...). If none of the Updaters change (see EqualUpdater), the
+// subscribers are not updated.
 func (s *Store) Dispatch(ctx context.Context, action interface{}) error {
-	errChan := make(chan error)
-	s.actionQueue <- queuedAction{ctx, action, errChan}
+	return s.queueActions(queuedAction{ctx: ctx, actions: []interface{}{action}}).wait()
+}
+
+// Dispatches each of the given actions, in order, to all of the Updaters in the state of the Store.
+// The State is only updated once all of the actions have been performed, so subscribers are only sent
+// a single update (where the action is a Batch). If an error is returned, then the State will not
+// change (even for the actions that had already completed) and the error will be a *BatchError.
+func (s *Store) DispatchBatch(ctx context.Context, actions ...interface{}) error {
+	if len(actions) == 0 {
+		return nil
+	}
+
+	return s.queueActions(queuedAction{ctx: ctx, actions: actions, isBatch: true}).wait()
+}
+
+// Adds the given action to the action queue, without waiting for it to be performed. The returned Future
+// can be used to get the result of the dispatch, see Dispatch(...). If the action queue is full, this
+// will wait for space in the queue unless the Store was configured with RejectWhenQueueFull(...).
+func (s *Store) DispatchAsync(ctx context.Context, action interface{}) *Future {
+	return s.queueActions(queuedAction{ctx: ctx, actions: []interface{}{action}})
+}
+
+// Adds the given queuedAction to the action queue. The returned Future will be completed when the
+// actions have been performed, or if they could not be added to the queue.
+func (s *Store) queueActions(queued queuedAction) *Future {
+	queued.result = newFuture()
+	if !s.startDispatch() {
+		queued.result.complete(ErrStoreClosed, 0)
+		return queued.result
+	}
+	defer s.pending.Done()
+
+	if s.rejectWhenFull {
+		select {
+		case s.actionQueue <- queued:
+		default:
+			queued.result.complete(ErrQueueFull, 0)
+		}
+
+		return queued.result
+	}
 
-	return <-errChan
+	select {
+	case s.actionQueue <- queued:
+	case <-queued.ctx.Done():
+		queued.result.complete(queued.ctx.Err(), 0)
+	}
+
+	return queued.result
 }
 
 // Select allows the given selector to pull its required data from the current State of the Store.
-func (s *Store) Select(sel Selector) {
+func (s *Store) Select(sel Selector) error {
+	if s.isClosing() {
+		return ErrStoreClosed
+	}
+
 	done := make(chan struct{})
-	s.accessState <- func(st *State) {
+	select {
+	case s.accessState <- func(st *State) {
 		defer close(done)
 
 		sel.SelectFrom(st)
+	}:
+	case <-s.closing:
+		return ErrStoreClosed
 	}
 	<-done
+
+	return nil
 }
 
-// Send a refrence to the Store to the given subscriber every time the State is updated.
-func (s *Store) Subscribe(sub subscriber) func() bool {
-	s.accessSubscribers <- func(subs *subscriberSet) {
-		subs.add(sub)
+// Send a refrence to the Store to the given subscriber every time the State is updated. The returned
+// function will unsubscribe the subscriber, and close it. A channel can only be subscribed once, until it
+// is unsubscribed.
+func (s *Store) Subscribe(sub subscriber) (func() bool, error) {
+	subscription, err := s.SubscribeWith(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription.Unsubscribe, nil
+}
+
+// Send a refrence to the Store to the given subscriber every time the State is updated, using the
+// given config functions to set how the updates are delivered (see Buffered(...), DropOldest(...),
+// DropNewest(...), CoalesceLatest(...) and DisconnectAfter(...)). Returns ErrAlreadySubscribed if the
+// channel already has a Subscription.
+func (s *Store) SubscribeWith(sub subscriber, configs ...func(*Subscription)) (*Subscription, error) {
+	return s.subscribe(sub, func(subs *subscriberSet) *Subscription {
+		return subs.add(sub, configs...)
+	})
+}
+
+// Send a refrence to the Store to the given subscriber every time the Updater for one of the given keys
+// is changed, see ForKeys(...).
+func (s *Store) SubscribeKeys(sub subscriber, keys ...interface{}) (*Subscription, error) {
+	return s.SubscribeWith(sub, ForKeys(keys...))
+}
+
+// Send a Change, which describes how the State was updated, to the given subscriber every time the
+// State is updated. The given config functions set how the updates are delivered, see SubscribeWith(...).
+func (s *Store) SubscribeChanges(sub chan<- Change, configs ...func(*Subscription)) (*Subscription, error) {
+	return s.subscribe(changeSubscriber(sub), func(subs *subscriberSet) *Subscription {
+		return subs.addChanges(sub, configs...)
+	})
+}
+
+// Adds a Subscription, using the given function, for the given subscriber channel.
+func (s *Store) subscribe(sub interface{}, addFn func(*subscriberSet) *Subscription) (*Subscription, error) {
+	if s.isClosing() {
+		return nil, ErrStoreClosed
+	}
+
+	subscription := make(chan *Subscription, 1)
+	select {
+	case s.accessSubscribers <- func(subs *subscriberSet) {
+		if _, hasSub := (*subs)[sub]; hasSub {
+			subscription <- nil
+			return
+		}
+
+		added := addFn(subs)
+		added.abandon = s.abandoned
+		added.counters = s.counters
+		atomic.AddInt64(&s.counters.subscribers, 1)
+
+		subscription <- added
+	}:
+	case <-s.closing:
+		return nil, ErrStoreClosed
 	}
 
-	return func() bool {
-		didUnsub := make(chan bool)
-		s.accessSubscribers <- func(subs *subscriberSet) {
+	added := <-subscription
+	if added == nil {
+		return nil, ErrAlreadySubscribed
+	}
+
+	added.unsubscribe = func() bool {
+		didUnsub := make(chan bool, 1)
+		select {
+		case s.accessSubscribers <- func(subs *subscriberSet) {
+			if (*subs)[sub] != added {
+				didUnsub <- false
+				return
+			}
+
 			didUnsub <- subs.remove(sub)
+		}:
+		case <-s.stopped:
+			return false
 		}
 		return <-didUnsub
 	}
+
+	return added, nil
+}
+
+// Shutdown stops the Store. Any calls to Dispatch(...) that have already been queued will be performed,
+// then every subscriber is closed and the goroutines used by the Store are stopped. After Shutdown(...)
+// is called, the methods of the Store will return ErrStoreClosed. If the given context is done before
+// the Store has stopped, its error is returned and any updates that the subscribers have not received are
+// dropped (so the Store will still finish shutting down, even if a subscriber is not receiving updates).
+func (s *Store) Shutdown(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		s.closeMu.Lock()
+		close(s.closing)
+		s.closeMu.Unlock()
+
+		go func() {
+			s.pending.Wait()
+			close(s.actionQueue)
+
+			s.routines.Wait()
+			close(s.done)
+		}()
+	})
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		s.abandonOnce.Do(func() {
+			close(s.abandoned)
+		})
+
+		return ctx.Err()
+	}
+}
+
+// A config function that will make the action queue of the Store hold up to the given number of actions,
+// so DispatchAsync(...) does not need to wait for the previous actions to be performed.
+func QueueCapacity(size int) func(*Store) {
+	return func(s *Store) {
+		s.actionQueue = make(chan queuedAction, size)
+	}
+}
+
+// A config function that will make the Store return ErrQueueFull when an action is dispatched while the
+// action queue is full, instead of waiting for space in the queue.
+func RejectWhenQueueFull(s *Store) {
+	s.rejectWhenFull = true
+}
+
+// A config function that will make the Store call the given function every time the State is updated,
+// before the subscribers are told about the Change. The function is called on the goroutine that performs
+// the actions, so the next action will not be performed until it returns.
+// NOTE: DO NOT call Dispatch(...) in the given function, it will wait forever.
+func OnCommit(hook func(Change)) func(*Store) {
+	return func(s *Store) {
+		s.commitHooks = append(s.commitHooks, hook)
+	}
+}
+
+// A config function that will make the Store call the given function every time an action is performed
+// without an error, after the OnCommit(...) hooks. Unlike OnCommit(...), it is also called for the actions
+// that did not change any of the Updaters, with a Change that has no ChangedKeys and a Sequence of 0.
+// Like OnCommit(...), the function is called on the goroutine that performs the actions.
+func OnDispatched(hook func(Change)) func(*Store) {
+	return func(s *Store) {
+		s.dispatchedHooks = append(s.dispatchedHooks, hook)
+	}
+}
+
+// A config function that will make the Store call the given function before the State is updated. If
+// the function returns an error, the State will not be updated and the error is returned from the dispatch.
+// It is not called for the actions that did not change any of the Updaters.
+// Like OnCommit(...), the function is called on the goroutine that performs the actions.
+func BeforeCommit(hook func(Change) error) func(*Store) {
+	return func(s *Store) {
+		s.beforeCommitHooks = append(s.beforeCommitHooks, hook)
+	}
+}
+
+// A config function that will make the sequence of the first Change, from the Store, start after the given
+// sequence. It should be used when the initial State was loaded from a Store that had already performed
+// actions.
+func StartSequence(sequence uint64) func(*Store) {
+	return func(s *Store) {
+		s.sequence = sequence
+	}
 }
 
-// A struct that contains an action wating to be dispatched to the Updaters. It also includes a channel
-// send any errors that occur, and is closed when the action is complete.
+// A config function that will make the Store call the given function every time an action returns an
+// error, so the State is not updated. If the error was from DispatchBatch(...), the action is a Batch. For a
+// partial commit (see PartialCommit(...)) it is called after the State is updated, see IsPartialCommit(...).
+// Like OnCommit(...), the function is called on the goroutine that performs the actions.
+func OnFailure(hook func(action interface{}, err error)) func(*Store) {
+	return func(s *Store) {
+		s.failureHooks = append(s.failureHooks, hook)
+	}
+}
+
+// Close stops the Store, see Shutdown(...).
+func (s *Store) Close() error {
+	return s.Shutdown(context.Background())
+}
+
+// Done returns a channel that is closed when Shutdown(...) or Close() is called on the Store.
+func (s *Store) Done() <-chan struct{} {
+	return s.closing
+}
+
+// Checks if Shutdown(...) has been called on the Store.
+func (s *Store) isClosing() bool {
+	select {
+	case <-s.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// Marks a call to Dispatch(...) as pending, so Shutdown(...) will wait for it. Returns false if the
+// Store is closing, in which case the action should not be queued.
+func (s *Store) startDispatch() bool {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+
+	if s.isClosing() {
+		return false
+	}
+
+	s.pending.Add(1)
+	return true
+}
+
+// A struct that contains the actions wating to be dispatched to the Updaters. It also includes a Future
+// that is completed when the actions are complete.
 type queuedAction struct {
-	ctx    context.Context
-	action interface{}
-	err    chan error
+	ctx     context.Context
+	actions []interface{}
+	isBatch bool
+	result  *Future
 }
 
 // A method that will list for actions in the action queue, and start a new goroutine to peform them when
-// the state is aviable.
+// the state is aviable. Once the action queue is closed, the other goroutines of the Store are stopped.
 func (s *Store) listenForActions() {
-	for curr := range s.actionQueue {
-		err := s.performAction(curr.ctx, curr.action)
-		if err != nil {
-			curr.err <- err
-		}
+	defer s.routines.Done()
+	defer close(s.stopped)
 
-		close(curr.err)
+	for curr := range s.actionQueue {
+		sequence, err := s.performActions(curr.ctx, curr.actions, curr.isBatch)
+		curr.result.complete(err, sequence)
 	}
 }
 
-// Perform the give action on the current State of the Store. It an error is returned,
-// the State will not be updated.
-func (s *Store) performAction(ctx context.Context, action interface{}) error {
-	// Perform the action on the current state
-	currState := State{}
-	s.Select(&currState)
+// Perform the given actions, in order, on the current State of the Store, and returns the sequence of the
+// Change. It an error is returned, the State will not be updated (unless the Store is using partial commits,
+// see PartialCommit(...)). If the actions are a batch, the Change sent to subscribers will have a Batch as
+// its action. If none of the Updaters changed, the actions are not committed and the sequence is 0.
+func (s *Store) performActions(ctx context.Context, actions []interface{}, isBatch bool) (uint64, error) {
+	// Perform the actions on a copy of the current state
+	var currState State
+	s.withState(func(st *State) {
+		currState = CopyState(*st)
+	})
+	prevState := CopyState(currState)
+
+	var action interface{} = Batch(actions)
+	if !isBatch {
+		action = actions[0]
+	}
+
+	// Batches are always rolled back as a whole
+	ctx = contextWithPartialCommit(ctx, !isBatch && (s.partialCommit || partialCommitFrom(ctx)))
+
+	updatedState, err := s.performOnCopy(ctx, currState, actions, isBatch)
+	if err != nil && updatedState == nil {
+		return 0, s.failed(action, err)
+	}
 
-	newState, err := s.PerformDispatch(ctx, currState, action)
+	change := Change{
+		Action:      action,
+		Previous:    prevState,
+		Current:     updatedState,
+		ChangedKeys: changedKeys(prevState, updatedState),
+		store:       s,
+	}
 	if err != nil {
-		return err
+		errors.As(err, &change.Partial)
 	}
 
-	// Update the store with the updated state
-	done := make(chan struct{})
-	s.accessState <- func(mutableSt *State) {
-		defer close(done)
+	// Nothing needs to be committed, or sent to subscribers, if none of the Updaters changed
+	if len(change.ChangedKeys) == 0 {
+		s.dispatched(change)
+		return 0, s.partiallyFailed(change, err)
+	}
 
-		for key, data := range newState {
-			(*mutableSt)[key] = data
+	change.Sequence = s.sequence + 1
+	for _, hook := range s.beforeCommitHooks {
+		if err := hook(change); err != nil {
+			return 0, s.failed(action, err)
 		}
 	}
-	<-done
+
+	// Update the store with the updated state
+	s.withState(func(mutableSt *State) {
+		*mutableSt = CopyState(updatedState)
+	})
+	s.sequence++
 
 	// Tell subscribers about the change
+	for _, hook := range s.commitHooks {
+		hook(change)
+	}
+	s.dispatched(change)
+
 	s.accessSubscribers <- func(subs *subscriberSet) {
-		subs.publish(s)
+		subs.publish(change)
 	}
 
-	return nil
+	return change.Sequence, s.partiallyFailed(change, err)
+}
+
+// Calls the dispatched hooks for the given Change.
+func (s *Store) dispatched(change Change) {
+	for _, hook := range s.dispatchedHooks {
+		hook(change)
+	}
+}
+
+// Calls the failure hooks for the given action, and returns the given error.
+func (s *Store) failed(action interface{}, err error) error {
+	for _, hook := range s.failureHooks {
+		hook(action, err)
+	}
+
+	return err
+}
+
+// Calls the failure hooks for the given Change, if it was a partial commit, and returns the given error.
+func (s *Store) partiallyFailed(change Change, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return s.failed(change.Action, err)
+}
+
+// Performs each of the given actions on the given State, using the State returned from the previous
+// action, and returns the full updated State. If the actions are a batch, any error will be a *BatchError.
+func (s *Store) performOnCopy(ctx context.Context, st State, actions []interface{}, isBatch bool) (State, error) {
+	if !isBatch {
+		return s.performAction(ctx, st, actions[0])
+	}
+
+	workingState := st
+	for i, action := range actions {
+		newState, err := s.performAction(ctx, workingState, action)
+		if err != nil {
+			return nil, &BatchError{Index: i, Action: action, Err: err}
+		}
+
+		workingState = newState
+	}
+
+	return workingState, nil
+}
+
+// Performs the given action on a copy of the given State, using PerformDispatch, and returns the full
+// updated State. Any keys that PerformDispatch returned with a nil Updater are removed from the State (see
+// ReplaceState(...)). For a partial commit, the State is returned with the *DispatchError.
+func (s *Store) performAction(ctx context.Context, st State, action interface{}) (State, error) {
+	newState, err := s.PerformDispatch(ctx, CopyState(st), action)
+	if err != nil && (newState == nil || !IsPartialCommit(err)) {
+		return nil, err
+	}
+
+	// Keep the previous version of any Updater that did not change
+	updatedState := CopyState(st)
+	for key, data := range newState {
+		if data == nil {
+			delete(updatedState, key)
+		} else if updaterChanged(st[key], data) {
+			updatedState[key] = data
+		}
+	}
+
+	return updatedState, err
+}
+
+// Calls the given function with the tracked State, and waits for it to return. Unlike Select(...), this
+// does not check if the Store is closing, so it should only be used by the goroutines of the Store.
+func (s *Store) withState(accessFn func(*State)) {
+	done := make(chan struct{})
+	s.accessState <- func(st *State) {
+		defer close(done)
+
+		accessFn(st)
+	}
+	<-done
 }
 
 // A method that will keep track of the state, which can only be accessed throught the accessState
 // channel. The data in the given State will be put into the tracked State to start.
 func (s *Store) trackState(initialState State) {
+	defer s.routines.Done()
+
 	currState := State{}
 	for key, data := range initialState {
 		currState[key] = data
 	}
 
-	for accessFn := range s.accessState {
-		accessFn(&currState)
+	for {
+		select {
+		case accessFn := <-s.accessState:
+			accessFn(&currState)
+		case <-s.stopped:
+			return
+		}
 	}
 }
 
 // A method that will keep track of the subscriberSet, which can only be accessed throught the
-// accessSubscribers channel.
+// accessSubscribers channel. When the Store is stopped, all the subscribers are closed.
 func (s *Store) trackSubscribers() {
-	subs := subscriberSet{}
+	defer s.routines.Done()
 
-	for accessFn := range s.accessSubscribers {
-		accessFn(&subs)
+	subs := subscriberSet{}
+	for {
+		select {
+		case accessFn := <-s.accessSubscribers:
+			accessFn(&subs)
+		case <-s.stopped:
+			subs.removeAll()
+			return
+		}
 	}
 }
@@ -2,7 +2,9 @@ package store
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -135,7 +137,10 @@ func TestStoreWillTrackSubscribers(t *testing.T) {
 
 	unsubs := []func() bool{}
 	for _, testSub := range testSubs {
-		unsub := st.Subscribe(testSub)
+		unsub, err := st.Subscribe(testSub)
+		if err != nil {
+			t.Fatal(err)
+		}
 
 		unsubs = append(unsubs, unsub)
 	}
@@ -175,6 +180,38 @@ func TestStoreWillTrackSubscribers(t *testing.T) {
 	}
 }
 
+func TestStoreWillNotSubscribeAChannelTwice(t *testing.T) {
+	st := New(State{"Updater 0": testUpdater{}})
+	defer st.Close()
+
+	testSub := make(chan *Store, 1)
+	unsubscribe, err := st.Subscribe(testSub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := st.Subscribe(testSub); err != ErrAlreadySubscribed {
+		t.Error("Subscribing the same channel again should return ErrAlreadySubscribed, but returned", err)
+	}
+	if _, err := st.SubscribeWith(testSub, Buffered(1)); err != ErrAlreadySubscribed {
+		t.Error("Subscribing the same channel with a buffer should return ErrAlreadySubscribed, but returned", err)
+	}
+
+	if err := st.Dispatch(context.Background(), "Test action"); err != nil {
+		t.Fatal(err)
+	}
+	if updated := <-testSub; updated != st {
+		t.Error("The original subscription should still be sent updates")
+	}
+
+	if !unsubscribe() {
+		t.Fatal("The original subscription could not be unsubscribed")
+	}
+	if _, isOpen := <-testSub; isOpen {
+		t.Error("The subscriber should be closed when it is unsubscribed")
+	}
+}
+
 func TestStoreWillUpdateSubscribers(t *testing.T) {
 	state := State{
 		"Updater 0": testUpdater{},
@@ -190,7 +227,9 @@ func TestStoreWillUpdateSubscribers(t *testing.T) {
 	wg.Add(subscribers)
 	for i := 0; i < subscribers; i++ {
 		testSub := make(chan *Store, actionPerSubscriber)
-		st.Subscribe(testSub)
+		if _, err := st.Subscribe(testSub); err != nil {
+			t.Fatal(err)
+		}
 
 		go func() {
 			defer wg.Done()
@@ -268,3 +307,372 @@ func TestStoreCanErrorDuringDispatch(t *testing.T) {
 		}
 	}
 }
+
+func TestStoreCanShutdown(t *testing.T) {
+	state := State{
+		"Updater 0": testUpdater{},
+	}
+
+	st := New(state)
+	testSub := make(chan *Store, 1)
+	if _, err := st.Subscribe(testSub); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := st.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, isOpen := <-testSub; isOpen {
+		t.Error("The subscriber was not closed when the Store was shutdown")
+	}
+
+	if err := st.Dispatch(context.Background(), "Test action"); err != ErrStoreClosed {
+		t.Error("The .Dispatch(...) method should have returned ErrStoreClosed, but returned", err)
+	}
+	if err := st.Select(&State{}); err != ErrStoreClosed {
+		t.Error("The .Select(...) method should have returned ErrStoreClosed, but returned", err)
+	}
+	if _, err := st.Subscribe(make(chan *Store)); err != ErrStoreClosed {
+		t.Error("The .Subscribe(...) method should have returned ErrStoreClosed, but returned", err)
+	}
+
+	if err := st.Close(); err != nil {
+		t.Error("Closing the Store more then once should not return an error, but returned", err)
+	}
+}
+
+func TestStoreShutdownWillDrainQueuedActions(t *testing.T) {
+	var performed int32
+	countPerformed := func(s *Store) {
+		performDispatch := s.PerformDispatch
+		s.PerformDispatch = func(ctx context.Context, st State, action interface{}) (State, error) {
+			atomic.AddInt32(&performed, 1)
+
+			return performDispatch(ctx, st, action)
+		}
+	}
+
+	st := New(State{"Updater 0": testUpdater{}}, countPerformed)
+	senders := 10
+
+	var completed int32
+	var wg sync.WaitGroup
+	wg.Add(senders)
+	for i := 0; i < senders; i++ {
+		go func(i int) {
+			defer wg.Done()
+
+			if err := st.Dispatch(context.Background(), i); err == nil {
+				atomic.AddInt32(&completed, 1)
+			} else if err != ErrStoreClosed {
+				t.Error("The .Dispatch(...) method returned an unexpected error:", err)
+			}
+		}(i)
+	}
+
+	time.Sleep(time.Millisecond)
+	if err := st.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if completed != performed {
+		t.Error(completed, "calls to .Dispatch(...) completed, but", performed, "actions were performed")
+	}
+}
+
+func TestStoreShutdownWillNotWaitForStalledSubscribers(t *testing.T) {
+	st := New(State{"Updater 0": testUpdater{}})
+
+	stalledSub := make(chan *Store)
+	subscription, err := st.SubscribeWith(stalledSub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The subscriber never reads, so the update is never delivered
+	if err := st.Dispatch(context.Background(), "Test action"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := st.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatal("The .Shutdown(...) method should have timed out, but returned", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := st.Shutdown(ctx); err != nil {
+		t.Error("The Store should finish shutting down after the first .Shutdown(...) timed out, but got", err)
+	}
+	if _, isOpen := <-stalledSub; isOpen {
+		t.Error("The stalled subscriber should have been closed")
+	}
+	if subscription.Dropped() != 1 {
+		t.Error("The update for the stalled subscriber should have been dropped, but dropped", subscription.Dropped())
+	}
+}
+
+func TestStoreWillNotBlockOnSlowSubscribers(t *testing.T) {
+	st := New(State{"Updater 0": testUpdater{}})
+	defer st.Close()
+
+	slowSub := make(chan *Store)
+	subscription, err := st.SubscribeWith(slowSub, Buffered(1), DropNewest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actions := 5
+	for i := 0; i < actions; i++ {
+		if err := st.Dispatch(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if subscription.Dropped() == 0 {
+		t.Error("The slow subscriber should have dropped some updates")
+	}
+
+	if !subscription.Unsubscribe() {
+		t.Error("The slow subscriber did not unsubscribe correctly")
+	}
+	if subscription.Unsubscribe() {
+		t.Error("The slow subscriber did not fail when unsubscribe more then once")
+	}
+}
+
+func TestStoreWillSendChangesToSubscribers(t *testing.T) {
+	st := New(State{
+		"Updater 0": testUpdater{},
+		"Unchanged": testValueUpdater("unchanged"),
+	})
+	defer st.Close()
+
+	changes := make(chan Change, 2)
+	if _, err := st.SubscribeChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+
+	testActions := []string{"Test action 0", "Test action 1"}
+	for _, testAction := range testActions {
+		if err := st.Dispatch(context.Background(), testAction); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, testAction := range testActions {
+		change := <-changes
+
+		if change.Action != testAction {
+			t.Error("The change, at index", i, "has the action", change.Action, "but should have", testAction)
+		}
+		if change.Sequence != uint64(i+1) {
+			t.Error("The change, at index", i, "has the sequence", change.Sequence, "but should have", i+1)
+		}
+		if prevActions := len(change.Previous["Updater 0"].(testUpdater).actions); prevActions != i {
+			t.Error("The previous state, at index", i, "has", prevActions, "actions but should have", i)
+		}
+		if currActions := len(change.Current["Updater 0"].(testUpdater).actions); currActions != i+1 {
+			t.Error("The current state, at index", i, "has", currActions, "actions but should have", i+1)
+		}
+		if !change.ChangedKeys.Has("Updater 0") || change.ChangedKeys.Has("Unchanged") {
+			t.Error("The change, at index", i, "has the incorrect changed keys:", change.ChangedKeys)
+		}
+	}
+}
+
+func TestStoreCanUnsubscribeChangeSubscribers(t *testing.T) {
+	st := New(State{"Updater 0": testUpdater{}})
+	defer st.Close()
+
+	changes := make(chan Change, 1)
+	subscription, err := st.SubscribeChanges(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.SubscribeChanges(changes); err != ErrAlreadySubscribed {
+		t.Error("Subscribing the same channel again should return ErrAlreadySubscribed, but returned", err)
+	}
+
+	if !subscription.Unsubscribe() {
+		t.Fatal("The change subscriber could not be unsubscribed")
+	}
+	if _, isOpen := <-changes; isOpen {
+		t.Error("The change subscriber should have been closed")
+	}
+}
+
+func TestStoreWillOnlyUpdateKeyedSubscribersOnChange(t *testing.T) {
+	st := New(State{
+		"Updater 0": testUpdater{},
+		"Unchanged": testValueUpdater("unchanged"),
+	})
+	defer st.Close()
+
+	changedSub := make(chan *Store, 1)
+	if _, err := st.SubscribeKeys(changedSub, "Updater 0"); err != nil {
+		t.Fatal(err)
+	}
+	unchangedSub := make(chan *Store, 1)
+	if _, err := st.SubscribeKeys(unchangedSub, "Unchanged"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := st.Dispatch(context.Background(), "Test action"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Subscribers are updated in order, so once this subscribes the other subscribers have been updated
+	if _, err := st.Subscribe(make(chan *Store)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changedSub) != 1 {
+		t.Error("The subscriber for the changed key should have been updated")
+	}
+	if len(unchangedSub) != 0 {
+		t.Error("The subscriber for the unchanged key should not have been updated")
+	}
+}
+
+func TestStoreWillCallCommitHooks(t *testing.T) {
+	var hookChanges []Change
+	var hookFailures []interface{}
+	st := New(State{
+		"Updater 0": testUpdater{},
+		"Updater 1": testFailOnUpdater{"Failed action"},
+	}, OnCommit(func(change Change) {
+		hookChanges = append(hookChanges, change)
+	}), OnFailure(func(action interface{}, err error) {
+		hookFailures = append(hookFailures, action)
+	}))
+
+	if err := st.Dispatch(context.Background(), "Test action"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Dispatch(context.Background(), "Failed action"); err == nil {
+		t.Fatal("The .Dispatch(...) method should have returned an error")
+	}
+	st.Close()
+
+	if len(hookChanges) != 1 {
+		t.Fatal("The commit hook should have been called once, but was called", len(hookChanges), "times")
+	}
+	if hookChanges[0].Action != "Test action" || hookChanges[0].Sequence != 1 {
+		t.Error("The commit hook was given the incorrect change:", hookChanges[0])
+	}
+
+	if len(hookFailures) != 1 || hookFailures[0] != "Failed action" {
+		t.Error("The failure hook should have been given the failed action, but was given", hookFailures)
+	}
+}
+
+func TestStoreBeforeCommitHooksCanStopTheCommit(t *testing.T) {
+	hookErr := errors.New("test hook error")
+	st := New(State{"Updater 0": testUpdater{}}, BeforeCommit(func(change Change) error {
+		if change.Action == "Rejected action" {
+			return hookErr
+		}
+
+		return nil
+	}))
+	defer st.Close()
+
+	if err := st.Dispatch(context.Background(), "Rejected action"); err != hookErr {
+		t.Error("The .Dispatch(...) method should have returned the error from the hook, but returned", err)
+	}
+
+	currState := State{}
+	st.Select(&currState)
+	if actions := currState["Updater 0"].(testUpdater).actions; len(actions) != 0 {
+		t.Error("The State should not have been updated when the hook returned an error")
+	}
+}
+
+func TestStoreCanStartAtASequence(t *testing.T) {
+	st := New(State{"Updater 0": testUpdater{}}, StartSequence(10))
+	defer st.Close()
+
+	f := st.DispatchAsync(context.Background(), "Test action")
+	if err := f.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Sequence() != 11 {
+		t.Error("The first action should have the sequence 11, but has", f.Sequence())
+	}
+}
+
+type testTouchUpdater struct {
+	id      int
+	touched int
+}
+
+func (u testTouchUpdater) Update(_ context.Context, _ interface{}) (Updater, error) {
+	return testTouchUpdater{u.id, u.touched + 1}, nil
+}
+
+func (u testTouchUpdater) Equal(other Updater) bool {
+	otherData, isTouchUpdater := other.(testTouchUpdater)
+
+	return isTouchUpdater && otherData.id == u.id
+}
+
+func TestStoreWillSkipActionsThatChangeNothing(t *testing.T) {
+	committed := make(chan Change, 2)
+	dispatched := make(chan Change, 2)
+	st := New(State{
+		"Updater 0": testTouchUpdater{id: 0},
+		"Updater 1": testValueUpdater("unchanged"),
+	}, OnCommit(func(change Change) {
+		committed <- change
+	}), OnDispatched(func(change Change) {
+		dispatched <- change
+	}))
+	defer st.Close()
+
+	changes := make(chan Change, 1)
+	if _, err := st.SubscribeChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := st.AddUpdater(context.Background(), "Updater 2", testValueUpdater("added")); err != nil {
+		t.Fatal(err)
+	}
+	<-changes
+	<-committed
+	<-dispatched
+
+	f := st.DispatchAsync(context.Background(), "Test action")
+	if err := f.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if f.Sequence() != 0 {
+		t.Error("An action that changed nothing should not have a sequence, but it is", f.Sequence())
+	}
+
+	change := <-dispatched
+	if change.Action != "Test action" || len(change.ChangedKeys) != 0 || change.Sequence != 0 {
+		t.Error("The dispatched hooks should be sent a Change, without a sequence, for the skipped action, but got", change)
+	}
+	if len(committed) != 0 {
+		t.Error("The commit hooks should not be called for the skipped action, but got", <-committed)
+	}
+
+	currState := State{}
+	st.Select(&currState)
+	if currState["Updater 0"].(testTouchUpdater).touched != 0 {
+		t.Error("An Updater that did not change should not be replaced")
+	}
+
+	// Subscribe after a dispatch, so any change from the skipped action would have been published
+	if _, err := st.Subscribe(make(chan *Store)); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Error("Subscribers should not be updated when nothing changed, but got", <-changes)
+	}
+}
@@ -1,32 +1,349 @@
 package store
 
+import (
+	"sync/atomic"
+	"time"
+)
+
 // A subscriber is a channel that will send the Store on updates.
 type subscriber chan<- *Store
 
-// A map that repecents a set of subscribers.
-type subscriberSet map[subscriber]struct{}
+// A changeSubscriber is a channel that will send a Change on updates.
+type changeSubscriber chan<- Change
+
+// A map that repecents a set of subscribers, keyed by the channel used to subscribe.
+type subscriberSet map[interface{}]*Subscription
+
+// Adds the given subscriber to the set, configured using the given config functions.
+func (subs *subscriberSet) add(sub subscriber, configs ...func(*Subscription)) *Subscription {
+	return subs.addSubscription(newSubscription(sub, storeSender(sub), configs...))
+}
 
-// Adds the given subscriber to the set.
-func (subs *subscriberSet) add(sub subscriber) {
-	(*subs)[sub] = struct{}{}
+// Adds the given change subscriber to the set, configured using the given config functions.
+func (subs *subscriberSet) addChanges(sub changeSubscriber, configs ...func(*Subscription)) *Subscription {
+	return subs.addSubscription(newSubscription(sub, changeSender(sub), configs...))
+}
+
+// Adds the given Subscription to the set.
+func (subs *subscriberSet) addSubscription(subscription *Subscription) *Subscription {
+	(*subs)[subscription.key] = subscription
+
+	return subscription
 }
 
 // Removes the given subscriber from the set. Returns false if the given subscription is
 // not in the set to remove.
-func (subs *subscriberSet) remove(sub subscriber) bool {
-	_, hasSub := (*subs)[sub]
+func (subs *subscriberSet) remove(sub interface{}) bool {
+	subscription, hasSub := (*subs)[sub]
 	if !hasSub {
 		return false
 	}
 
-	close(sub)
+	subscription.close()
 	delete(*subs, sub)
 	return true
 }
 
-// Sends the given Store to all of the set's subscribers.
-func (subs *subscriberSet) publish(st *Store) {
-	for sub, _ := range *subs {
-		sub <- st
+// Removes, and closes, all of the subscribers in the set.
+func (subs *subscriberSet) removeAll() {
+	for sub := range *subs {
+		subs.remove(sub)
+	}
+}
+
+// Sends the given Change to all of the set's subscribers. Any subscribers that have been disconnected,
+// because of their delivery policy, will be removed from the set.
+func (subs *subscriberSet) publish(change Change) {
+	for sub, subscription := range *subs {
+		if !subscription.notify(change) {
+			subs.remove(sub)
+		}
+	}
+}
+
+// A sender delivers a Change to a subscriber channel, unless the given stop channel is closed first.
+// Returns false if the Change was not delivered.
+type sender struct {
+	send  func(change Change, stop <-chan struct{}) bool
+	close func()
+}
+
+// Creates a sender that will send the Store, the Change is from, to the given subscriber.
+func storeSender(sub subscriber) sender {
+	return sender{
+		send: func(change Change, stop <-chan struct{}) bool {
+			select {
+			case sub <- change.store:
+				return true
+			case <-stop:
+				return false
+			}
+		},
+		close: func() { close(sub) },
+	}
+}
+
+// Creates a sender that will send the whole Change to the given subscriber.
+func changeSender(sub changeSubscriber) sender {
+	return sender{
+		send: func(change Change, stop <-chan struct{}) bool {
+			select {
+			case sub <- change:
+				return true
+			case <-stop:
+				return false
+			}
+		},
+		close: func() { close(sub) },
+	}
+}
+
+// The ways a Subscription can deliver updates when its subscriber is not ready to receive them.
+type deliveryPolicy int
+
+const (
+	blockPolicy deliveryPolicy = iota
+	dropOldestPolicy
+	dropNewestPolicy
+	coalescePolicy
+	disconnectPolicy
+)
+
+// A Subscription keeps track of how updates are delivered to a subscriber of a Store. By default, the
+// Store will wait for the subscriber to receive each update, but config functions can be passed to
+// Store.SubscribeWith(...) to queue the updates instead.
+type Subscription struct {
+	key         interface{}
+	sender      sender
+	policy      deliveryPolicy
+	bufferSize  int
+	timeout     time.Duration
+	queue       chan Change
+	stop        chan struct{}
+	delivered   chan struct{}
+	abandon     <-chan struct{}
+	dropped     uint64
+	counters    *subscriberCounters
+	filters     []func(Change) bool
+	unsubscribe func() bool
+}
+
+// A config function for a Subscription that will queue up to the given number of updates for
+// the subscriber. When the queue is full, the Store will wait for the subscriber unless a different
+// policy is given.
+func Buffered(size int) func(*Subscription) {
+	return func(s *Subscription) {
+		s.bufferSize = size
+	}
+}
+
+// A config function for a Subscription that will remove the oldest queued update when the queue is
+// full.
+func DropOldest(s *Subscription) {
+	s.policy = dropOldestPolicy
+}
+
+// A config function for a Subscription that will not queue any new updates when the queue is full.
+func DropNewest(s *Subscription) {
+	s.policy = dropNewestPolicy
+}
+
+// A config function for a Subscription that will only keep the latest update, so a slow subscriber
+// is only told that the Store has changed once.
+func CoalesceLatest(s *Subscription) {
+	s.policy = coalescePolicy
+	s.bufferSize = 1
+}
+
+// A config function for a Subscription that will unsubscribe the subscriber if the queue is still
+// full after the given timeout.
+func DisconnectAfter(timeout time.Duration) func(*Subscription) {
+	return func(s *Subscription) {
+		s.policy = disconnectPolicy
+		s.timeout = timeout
+	}
+}
+
+// A config function for a Subscription that will only send updates when the Updater for one of the
+// given keys has changed (see EqualUpdater).
+func ForKeys(keys ...interface{}) func(*Subscription) {
+	return func(s *Subscription) {
+		s.filters = append(s.filters, func(change Change) bool {
+			for _, key := range keys {
+				if change.ChangedKeys.Has(key) {
+					return true
+				}
+			}
+
+			return false
+		})
+	}
+}
+
+// A config function for a Subscription that will only send updates when the given function returns
+// true for one of the changed Updaters. The prevData or currData will be nil if the key was added or
+// removed from the State.
+func WhenChanged(changedFn func(key interface{}, prevData, currData Updater) bool) func(*Subscription) {
+	return func(s *Subscription) {
+		s.filters = append(s.filters, func(change Change) bool {
+			for key := range change.ChangedKeys {
+				if changedFn(key, change.Previous[key], change.Current[key]) {
+					return true
+				}
+			}
+
+			return false
+		})
+	}
+}
+
+// Creates a new Subscription for the given subscriber channel, that uses the given sender. If any of the
+// configs require the updates to be queued, a goroutine will be started to deliver them.
+func newSubscription(key interface{}, sender sender, configs ...func(*Subscription)) *Subscription {
+	s := &Subscription{key: key, sender: sender}
+	for _, config := range configs {
+		config(s)
+	}
+
+	if s.policy == blockPolicy && s.bufferSize == 0 {
+		return s
+	}
+	if s.bufferSize < 1 {
+		s.bufferSize = 1
+	}
+
+	s.queue = make(chan Change, s.bufferSize)
+	s.stop = make(chan struct{})
+	s.delivered = make(chan struct{})
+	go s.deliver()
+
+	return s
+}
+
+// Dropped returns the number of updates that were not delivered to the subscriber because of its
+// delivery policy.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Unsubscribe removes the subscriber from the Store, and closes it. Returns false if the subscriber
+// was already removed.
+func (s *Subscription) Unsubscribe() bool {
+	if s.unsubscribe == nil {
+		return false
+	}
+
+	return s.unsubscribe()
+}
+
+// Sends, or queues, the given Change for the subscriber. Returns false if the subscriber should be
+// disconnected.
+func (s *Subscription) notify(change Change) bool {
+	for _, filter := range s.filters {
+		if !filter(change) {
+			return true
+		}
+	}
+
+	if s.queue == nil {
+		if !s.sender.send(change, s.abandon) {
+			s.drop()
+		}
+		return true
 	}
+
+	switch s.policy {
+	case dropOldestPolicy, coalescePolicy:
+		for {
+			select {
+			case s.queue <- change:
+				s.count(1)
+				return true
+			default:
+			}
+
+			select {
+			case <-s.queue:
+				s.count(-1)
+				s.drop()
+			default:
+			}
+		}
+	case dropNewestPolicy:
+		select {
+		case s.queue <- change:
+			s.count(1)
+		default:
+			s.drop()
+		}
+		return true
+	case disconnectPolicy:
+		timer := time.NewTimer(s.timeout)
+		defer timer.Stop()
+
+		select {
+		case s.queue <- change:
+			s.count(1)
+			return true
+		case <-timer.C:
+			s.drop()
+			return false
+		}
+	default:
+		select {
+		case s.queue <- change:
+			s.count(1)
+		case <-s.abandon:
+			s.drop()
+		}
+		return true
+	}
+}
+
+// Counts an update that was dropped, for the Subscription and the Store.
+func (s *Subscription) drop() {
+	atomic.AddUint64(&s.dropped, 1)
+	if s.counters != nil {
+		atomic.AddUint64(&s.counters.dropped, 1)
+	}
+}
+
+// Adds the given number of updates to the backlog of the Store.
+func (s *Subscription) count(queued int64) {
+	if s.counters != nil {
+		atomic.AddInt64(&s.counters.backlog, queued)
+	}
+}
+
+// A method that will send the queued updates to the subscriber, until the Subscription is closed.
+func (s *Subscription) deliver() {
+	defer close(s.delivered)
+	defer s.sender.close()
+
+	for {
+		select {
+		case change := <-s.queue:
+			s.count(-1)
+			s.sender.send(change, s.stop)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Closes the subscriber, any queued updates will not be delivered. Waits for the goroutine that delivers
+// the queued updates to stop, so the updates left in the queue are no longer counted once it returns.
+func (s *Subscription) close() {
+	if s.counters != nil {
+		atomic.AddInt64(&s.counters.subscribers, -1)
+	}
+
+	if s.queue == nil {
+		s.sender.close()
+		return
+	}
+
+	close(s.stop)
+	<-s.delivered
+	s.count(-int64(len(s.queue)))
 }
@@ -3,6 +3,7 @@ package store
 import (
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestSubscriberSetCanSubscribe(t *testing.T) {
@@ -94,7 +95,159 @@ func TestSubscriberSetCanPublish(t *testing.T) {
 
 	st := New(state)
 	for j := 0; j < updatesPreSubscribers; j++ {
-		subs.publish(st)
+		subs.publish(Change{store: st})
 	}
 	wg.Wait()
 }
+
+func TestSubscriptionCanBuffer(t *testing.T) {
+	sub := make(chan *Store)
+	subscription := newSubscription(sub, storeSender(sub), Buffered(3))
+	defer subscription.close()
+
+	st := &Store{}
+	for i := 0; i < 3; i++ {
+		if !subscription.notify(Change{store: st}) {
+			t.Fatal("The subscription should not have been disconnected")
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if <-sub != st {
+			t.Error("The subscriber was given the incorrect Store, at index", i)
+		}
+	}
+}
+
+func TestSubscriptionCanDropNewest(t *testing.T) {
+	sub := make(chan *Store)
+	subscription := newSubscription(sub, storeSender(sub), Buffered(2), DropNewest)
+	defer subscription.close()
+
+	// The delivery goroutine may be holding one update, waiting for the subscriber
+	updates := 10
+	for i := 0; i < updates; i++ {
+		subscription.notify(Change{store: &Store{}})
+	}
+
+	if dropped := subscription.Dropped(); dropped < uint64(updates-3) {
+		t.Error("At least", updates-3, "updates should have been dropped, but", dropped, "were")
+	}
+}
+
+func TestSubscriptionCanDropOldest(t *testing.T) {
+	sub := make(chan *Store)
+	subscription := newSubscription(sub, storeSender(sub), Buffered(1), DropOldest)
+	defer subscription.close()
+
+	var last *Store
+	updates := 10
+	for i := 0; i < updates; i++ {
+		last = &Store{}
+		subscription.notify(Change{store: last})
+	}
+
+	// The delivery goroutine may be holding the first update, waiting for the subscriber
+	received := <-sub
+	if received != last {
+		received = <-sub
+	}
+	if received != last {
+		t.Error("The subscriber should have been given the newest update")
+	}
+
+	if dropped := subscription.Dropped(); dropped < uint64(updates-2) {
+		t.Error("At least", updates-2, "updates should have been dropped, but", dropped, "were")
+	}
+}
+
+func TestSubscriptionCanCoalesce(t *testing.T) {
+	sub := make(chan *Store)
+	subscription := newSubscription(sub, storeSender(sub), Buffered(5), CoalesceLatest)
+	defer subscription.close()
+
+	if cap(subscription.queue) != 1 {
+		t.Error("A coalescing subscription should only queue 1 update, but can queue", cap(subscription.queue))
+	}
+
+	for i := 0; i < 10; i++ {
+		if !subscription.notify(Change{store: &Store{}}) {
+			t.Fatal("The subscription should not have been disconnected")
+		}
+	}
+}
+
+func TestSubscriptionCanDisconnect(t *testing.T) {
+	sub := make(chan *Store)
+	subscription := newSubscription(sub, storeSender(sub), DisconnectAfter(time.Millisecond))
+
+	disconnected := false
+	for i := 0; i < 3 && !disconnected; i++ {
+		disconnected = !subscription.notify(Change{store: &Store{}})
+	}
+
+	if !disconnected {
+		t.Fatal("The subscription should have been disconnected when the subscriber stopped receiving updates")
+	}
+	if subscription.Dropped() != 1 {
+		t.Error("The subscription should have dropped 1 update, but dropped", subscription.Dropped())
+	}
+
+	subscription.close()
+	for range sub {
+	}
+}
+
+func TestSubscriberSetRemovesDisconnectedSubscribers(t *testing.T) {
+	sub := make(chan *Store)
+	subs := &subscriberSet{}
+	subs.add(sub, DisconnectAfter(time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		subs.publish(Change{store: &Store{}})
+	}
+
+	if _, hasSub := (*subs)[sub]; hasSub {
+		t.Error("The disconnected subscriber should have been removed from the set")
+	}
+
+	// Any updates the delivery goroutine was holding might still be sent, before it is closed
+	for range sub {
+	}
+}
+
+func TestSubscriptionCanFilterByKeys(t *testing.T) {
+	sub := make(chan *Store, 2)
+	subscription := newSubscription(sub, storeSender(sub), ForKeys("Updater 0", "Updater 1"))
+
+	subscription.notify(Change{ChangedKeys: KeySet{"Updater 2": struct{}{}}})
+	subscription.notify(Change{ChangedKeys: KeySet{"Updater 1": struct{}{}}})
+	subscription.close()
+
+	if sent := len(sub); sent != 1 {
+		t.Error("Only 1 update should have been sent to the subscriber, but", sent, "were")
+	}
+}
+
+func TestSubscriptionCanFilterWithAFunction(t *testing.T) {
+	sub := make(chan *Store, 2)
+	subscription := newSubscription(sub, storeSender(sub), WhenChanged(func(_ interface{}, prevData, _ Updater) bool {
+		return prevData == nil
+	}))
+
+	subscription.notify(Change{
+		Previous:    State{"Updater 0": testValueUpdater("prev")},
+		Current:     State{"Updater 0": testValueUpdater("curr")},
+		ChangedKeys: KeySet{"Updater 0": struct{}{}},
+	})
+	subscription.notify(Change{
+		Previous:    State{},
+		Current:     State{"Updater 0": testValueUpdater("curr")},
+		ChangedKeys: KeySet{"Updater 0": struct{}{}},
+	})
+	subscription.close()
+
+	if sent := len(sub); sent != 1 {
+		t.Error("Only 1 update should have been sent to the subscriber, but", sent, "were")
+	}
+}
@@ -0,0 +1,115 @@
+package store
+
+import "context"
+
+// A Reducer creates the next version of the root state of a TypedStore for the given action.
+type Reducer[S, A any] func(context.Context, S, A) (S, error)
+
+// A TypedStore is a Store where the State is a single value of type S, and the actions are of type A.
+// It is built on top of a Store, so any configuration function (i.e. middleware.Apply(...)) can be used
+// with it.
+type TypedStore[S, A any] struct {
+	store *Store
+}
+
+// Creates a new TypedStore that starts with the given state, and uses the given Reducer to perform
+// actions.
+func NewTyped[S, A any](initialState S, reducer Reducer[S, A], configs ...func(*Store)) *TypedStore[S, A] {
+	return &TypedStore[S, A]{
+		store: New(State{typedStateKey{}: typedUpdater[S, A]{initialState, reducer}}, configs...),
+	}
+}
+
+// Store returns the untyped Store that the TypedStore is built on.
+func (ts *TypedStore[S, A]) Store() *Store {
+	return ts.store
+}
+
+// Dispatches the given action to the Reducer of the TypedStore, see Store.Dispatch(...).
+func (ts *TypedStore[S, A]) Dispatch(ctx context.Context, action A) error {
+	return ts.store.Dispatch(ctx, action)
+}
+
+// Select returns the current state of the TypedStore.
+func (ts *TypedStore[S, A]) Select() (S, error) {
+	sel := &typedSelector[S, A]{}
+	err := ts.store.Select(sel)
+
+	return sel.value, err
+}
+
+// Send the state to the given subscriber every time it is updated. The given config functions set how
+// updates are delivered to the subscriber, see Store.SubscribeWith(...). The returned function will
+// unsubscribe the subscriber, and close it.
+func (ts *TypedStore[S, A]) Subscribe(sub chan<- S, configs ...func(*Subscription)) (func() bool, error) {
+	subscription, err := ts.store.subscribe(sub, func(subs *subscriberSet) *Subscription {
+		return subs.addSubscription(newSubscription(sub, typedSender[S, A](sub), configs...))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription.Unsubscribe, nil
+}
+
+// Shutdown stops the TypedStore, see Store.Shutdown(...).
+func (ts *TypedStore[S, A]) Shutdown(ctx context.Context) error {
+	return ts.store.Shutdown(ctx)
+}
+
+// Close stops the TypedStore, see Store.Close().
+func (ts *TypedStore[S, A]) Close() error {
+	return ts.store.Close()
+}
+
+// The key the root state of a TypedStore is stored under in the State of its Store.
+type typedStateKey struct{}
+
+// An Updater that uses a Reducer to update a typed value. Any action that is not of type A is ignored.
+type typedUpdater[S, A any] struct {
+	value   S
+	reducer Reducer[S, A]
+}
+
+func (u typedUpdater[S, A]) Update(ctx context.Context, action interface{}) (Updater, error) {
+	typedAction, isTyped := action.(A)
+	if !isTyped {
+		return u, nil
+	}
+
+	newValue, err := u.reducer(ctx, u.value, typedAction)
+	if err != nil {
+		return nil, err
+	}
+
+	return typedUpdater[S, A]{newValue, u.reducer}, nil
+}
+
+// Creates a sender that will send the root state, from the Change, to the given subscriber of a TypedStore.
+func typedSender[S, A any](sub chan<- S) sender {
+	return sender{
+		send: func(change Change, stop <-chan struct{}) bool {
+			sel := &typedSelector[S, A]{}
+			sel.SelectFrom(&change.Current)
+
+			select {
+			case sub <- sel.value:
+				return true
+			case <-stop:
+				return false
+			}
+		},
+		close: func() { close(sub) },
+	}
+}
+
+// A Selector that pulls the root state out of the State of a TypedStore.
+type typedSelector[S, A any] struct {
+	value S
+}
+
+func (sel *typedSelector[S, A]) SelectFrom(st *State) {
+	if data, hasData := (*st)[typedStateKey{}].(typedUpdater[S, A]); hasData {
+		sel.value = data.value
+	}
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testTypedState struct {
+	count   int
+	actions []string
+}
+
+type testTypedAction string
+
+func testTypedReducer(_ context.Context, st testTypedState, action testTypedAction) (testTypedState, error) {
+	if action == "error" {
+		return st, errors.New("The testTypedReducer correctly return this error")
+	}
+
+	return testTypedState{st.count + 1, append(st.actions, string(action))}, nil
+}
+
+func TestTypedStoreGivenInitialState(t *testing.T) {
+	st := NewTyped(testTypedState{count: 10}, testTypedReducer)
+	defer st.Close()
+
+	currState, err := st.Select()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if currState.count != 10 {
+		t.Error("The TypedStore should have started with a count of 10, but has", currState.count)
+	}
+}
+
+func TestTypedStoreWillCallReducer(t *testing.T) {
+	st := NewTyped(testTypedState{}, testTypedReducer)
+	defer st.Close()
+
+	testActions := []testTypedAction{"action 0", "action 1", "action 2"}
+	for _, testAction := range testActions {
+		if err := st.Dispatch(context.Background(), testAction); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	currState, err := st.Select()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if currState.count != len(testActions) {
+		t.Error("The reducer should have been called", len(testActions), "times, but was called", currState.count)
+	}
+	for i, action := range currState.actions {
+		if action != string(testActions[i]) {
+			t.Error("The reducer was called with", action, "but should have been called with", testActions[i])
+		}
+	}
+}
+
+func TestTypedStoreCanErrorDuringDispatch(t *testing.T) {
+	st := NewTyped(testTypedState{}, testTypedReducer)
+	defer st.Close()
+
+	if err := st.Dispatch(context.Background(), "error"); err == nil {
+		t.Error("The .Dispatch(...) method should have retuned an error when the reducer does")
+	}
+
+	currState, err := st.Select()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if currState.count != 0 {
+		t.Error("The state should not have changed when the reducer returned an error")
+	}
+}
+
+func TestTypedStoreIgnoresUntypedActions(t *testing.T) {
+	st := NewTyped(testTypedState{}, testTypedReducer)
+	defer st.Close()
+
+	if err := st.Store().Dispatch(context.Background(), 42); err != nil {
+		t.Fatal(err)
+	}
+
+	currState, err := st.Select()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if currState.count != 0 {
+		t.Error("The reducer should not have been called with an action that is not a testTypedAction")
+	}
+}
+
+func TestTypedStoreWillUpdateSubscribers(t *testing.T) {
+	st := NewTyped(testTypedState{}, testTypedReducer)
+
+	testSub := make(chan testTypedState)
+	if _, err := st.Subscribe(testSub); err != nil {
+		t.Fatal(err)
+	}
+
+	go st.Dispatch(context.Background(), "action 0")
+
+	updatedState := <-testSub
+	if updatedState.count != 1 {
+		t.Error("The subscriber should have been given a count of 1, but was given", updatedState.count)
+	}
+
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, isOpen := <-testSub; isOpen {
+		t.Error("The subscriber was not closed when the TypedStore was shutdown")
+	}
+}
+
+func TestTypedStoreClosesStalledSubscribersWhenUnsubscribed(t *testing.T) {
+	st := NewTyped(testTypedState{}, testTypedReducer)
+	defer st.Close()
+
+	testSub := make(chan testTypedState)
+	unsubscribe, err := st.Subscribe(testSub, Buffered(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The subscriber never reads the updates
+	st.Dispatch(context.Background(), "action 0")
+	st.Dispatch(context.Background(), "action 1")
+
+	if !unsubscribe() {
+		t.Fatal("The stalled subscriber could not be unsubscribed")
+	}
+	if _, isOpen := <-testSub; isOpen {
+		t.Error("The stalled subscriber should have been closed")
+	}
+}
+
+func TestTypedStoreClosesStalledSubscribersWhenShutdownGivesUp(t *testing.T) {
+	st := NewTyped(testTypedState{}, testTypedReducer)
+
+	testSub := make(chan testTypedState)
+	if _, err := st.Subscribe(testSub); err != nil {
+		t.Fatal(err)
+	}
+	st.Dispatch(context.Background(), "action 0")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := st.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Error("The .Shutdown(...) method should have timed out, but returned", err)
+	}
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, isOpen := <-testSub; isOpen {
+		t.Error("The stalled subscriber should have been closed")
+	}
+}
@@ -12,3 +12,21 @@ type Updater interface {
 	// NOTE: Because Updaters should be immutable, always create a new version when an update occures.
 	Update(ctx context.Context, ac interface{}) (Updater, error)
 }
+
+// An Updater that can check if it is equal to another Updater. It is used to check which Updaters have
+// changed after an action is dispatched, Updaters that do not implement it are compared using ==.
+type EqualUpdater interface {
+	Updater
+
+	// Checks if the given Updater contains the same data as this Updater.
+	Equal(other Updater) bool
+}
+
+// An Updater that has a version, which changes every time its data changes. It is used to check which
+// Updaters have changed, when comparing the data would be too slow (or it can not be compared using ==).
+type VersionedUpdater interface {
+	Updater
+
+	// Gets the version of the data in this Updater.
+	Version() uint64
+}